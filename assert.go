@@ -0,0 +1,19 @@
+//go:build !prob_noassert
+// +build !prob_noassert
+
+package prob
+
+// checks reports whether assertions are enabled. Checks which are
+// expensive to evaluate, e.g., FullySupported(d), are guarded by it,
+// so that they compile away under the prob_noassert build tag.
+const checks = true
+
+// assert is a helper function to provide
+// moderate runtime type checking on the Element interface
+//
+// Building with the prob_noassert tag disables these checks.
+func assert(flag bool, s string) {
+	if !flag {
+		panic(s)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build prob_noassert
+// +build prob_noassert
+
+package prob
+
+// checks is false under the prob_noassert build tag, so the
+// expensive checks it guards are never evaluated.
+const checks = false
+
+// assert is disabled by the prob_noassert build tag, so a failed
+// check no longer panics, and invalid input is no longer caught: use
+// this only once the program is known to respect the package's
+// invariants.
+func assert(flag bool, s string) {}
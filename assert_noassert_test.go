@@ -0,0 +1,37 @@
+//go:build prob_noassert
+// +build prob_noassert
+
+package prob
+
+import (
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+// assertPanics skips the test, as the checks which would panic are
+// disabled by the prob_noassert build tag. Use it only in tests which
+// check for panics alone, so that no other check is skipped.
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	t.Skip("assertions disabled by the prob_noassert build tag")
+}
+
+func TestAssert(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("unexpected panic: %v", r)
+		}
+	}()
+
+	assert(false, "failed")
+
+	// an over-supporting outcome is recorded, rather than rejected
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+	d.AddOutcome(1, 0.75)
+	d.AddOutcome(2, 0.75)
+
+	if s := Support(d); !equiv(float64(s), 1.5) {
+		t.Errorf("Support(d) = %f, want 1.5", s)
+	}
+}
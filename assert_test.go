@@ -0,0 +1,38 @@
+//go:build !prob_noassert
+// +build !prob_noassert
+
+package prob
+
+import (
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+// assertPanics fails the test if f does not panic
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+
+	f()
+}
+
+func TestAssert(t *testing.T) {
+	assertPanics(t, func() {
+		assert(false, "failed")
+	})
+
+	assertPanics(t, func() {
+		Multinomial(0.5, 0.5)(1)
+	})
+
+	assertPanics(t, func() {
+		d := NewDiscreteDistribution(set.WithElements(1, 2))
+		d.AddOutcome(1, 1.5)
+	})
+}
@@ -0,0 +1,39 @@
+package prob
+
+// --- Builder {{{
+
+// A Builder accumulates weighted outcomes, and builds a distribution
+// from them, normalizing the weights so they need not sum to 1.
+//
+//	d := NewBuilder().
+//		Add("heads", 3).
+//		Add("tails", 1).
+//		Build() // heads w.p. 0.75, tails w.p. 0.25
+type Builder struct {
+	outcomes Outcomes
+	weights  []float64
+}
+
+// NewBuilder constructs an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Add adds the outcome o with the non-negative weight. Adding an
+// outcome again accumulates its weight.
+func (b *Builder) Add(o Outcome, weight float64) *Builder {
+	assert(weight >= 0, "negative weight")
+
+	b.outcomes = append(b.outcomes, o)
+	b.weights = append(b.weights, weight)
+
+	return b
+}
+
+// Build constructs the distribution over the outcomes added, each
+// with probability proportional to its total weight. See NewCategorical.
+func (b *Builder) Build() DiscreteDistribution {
+	return NewCategorical(b.outcomes, b.weights)
+}
+
+// --- }}}
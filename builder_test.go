@@ -0,0 +1,22 @@
+package prob
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	d := NewBuilder().Add("heads", 3).Add("tails", 1).Build()
+
+	if p := d.ProbabilityOf("heads"); !equiv(float64(p), 0.75) {
+		t.Errorf("P(heads) = %f, want 0.75", p)
+	}
+
+	if p := d.ProbabilityOf("tails"); !equiv(float64(p), 0.25) {
+		t.Errorf("P(tails) = %f, want 0.25", p)
+	}
+
+	// adding an outcome again accumulates its weight
+	d = NewBuilder().Add("heads", 1).Add("tails", 1).Add("heads", 2).Build()
+
+	if p := d.ProbabilityOf("heads"); !equiv(float64(p), 0.75) {
+		t.Errorf("P(heads) = %f, want 0.75 after accumulating", p)
+	}
+}
@@ -0,0 +1,213 @@
+package prob
+
+import "math"
+
+// --- Types {{{
+
+// A ContinuousDistribution is the interface for interacting with a
+// probability distribution over the real numbers, defined by its
+// density rather than by the probability of individual outcomes.
+type ContinuousDistribution interface {
+	// Density returns the probability density function (PDF) of
+	// the distribution, evaluated at x.
+	Density(x float64) float64
+
+	// CDF returns the cumulative distribution function of the
+	// distribution, evaluated at x. That is, P(X ≤ x).
+	CDF(x float64) float64
+
+	// Support returns the bounds of the interval outside of which
+	// the density is zero. Either bound may be infinite.
+	Support() (lo, hi float64)
+}
+
+// --- }}}
+
+// --- Normal {{{
+
+// Normal constructs the normal (Gaussian) distribution with mean mu
+// and standard deviation sigma.
+//
+// Recall: f(x) = exp(-(x-μ)²/2σ²) / (σ√(2π))
+func Normal(mu, sigma float64) ContinuousDistribution {
+	assert(sigma > 0, "standard deviation must be positive")
+
+	return &normal{mu: mu, sigma: sigma}
+}
+
+// normal structure serves as an implementation of the
+// ContinuousDistribution interface for normal distributions
+type normal struct {
+	mu, sigma float64
+}
+
+func (n *normal) Density(x float64) float64 {
+	z := (x - n.mu) / n.sigma
+	return math.Exp(-z*z/2) / (n.sigma * math.Sqrt(2*math.Pi))
+}
+
+func (n *normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-n.mu)/(n.sigma*math.Sqrt2)) / 2
+}
+
+func (n *normal) Support() (lo, hi float64) {
+	return math.Inf(-1), math.Inf(1)
+}
+
+// --- }}}
+
+// --- Composition {{{
+
+// ComposeContinuous takes two continuous distributions, p and q, and
+// creates a third, n, which is the mixture drawing from p with
+// probability alpha and from q with probability 1-alpha. It is the
+// continuous analog of Compose.
+//
+// f_n(x) = alpha*f_p(x) + (1-alpha)*f_q(x), and likewise for the CDF.
+// The support of n is the smallest interval containing the supports
+// of both p and q.
+func ComposeContinuous(p, q ContinuousDistribution, alpha float64) ContinuousDistribution {
+	assert(Probability(alpha).Valid(), "invalid mixing probability")
+
+	return &composition{p: p, q: q, alpha: alpha}
+}
+
+// composition structure serves as an implementation of the
+// ContinuousDistribution interface for mixtures of two distributions
+type composition struct {
+	p, q  ContinuousDistribution
+	alpha float64
+}
+
+func (c *composition) Density(x float64) float64 {
+	return c.alpha*c.p.Density(x) + (1-c.alpha)*c.q.Density(x)
+}
+
+func (c *composition) CDF(x float64) float64 {
+	return c.alpha*c.p.CDF(x) + (1-c.alpha)*c.q.CDF(x)
+}
+
+func (c *composition) Support() (lo, hi float64) {
+	plo, phi := c.p.Support()
+	qlo, qhi := c.q.Support()
+
+	return math.Min(plo, qlo), math.Max(phi, qhi)
+}
+
+// --- }}}
+
+// --- Random Variables {{{
+
+// ContinuousExpectation computes the expected value of a function, X,
+// of the outcome of a continuous distribution c.
+//
+// Recall: E[X] = ∫ X(x)f(x) dx
+//
+// The integral is approximated by the midpoint rule, with intervals
+// of width step over the support of c. Smaller steps are more accurate,
+// but slower. Infinite supports are truncated where the mass in each
+// tail is negligible.
+func ContinuousExpectation(c ContinuousDistribution, X func(float64) float64, step float64) float64 {
+	return integrate(c, X, step)
+}
+
+// ContinuousVariance computes the variance of a function, X, of the
+// outcome of a continuous distribution c. See ContinuousExpectation
+// for the meaning of step.
+//
+// Recall: Var(X) = E(X^2) - E(X)^2
+func ContinuousVariance(c ContinuousDistribution, X func(float64) float64, step float64) float64 {
+	square := func(x float64) float64 {
+		return X(x) * X(x)
+	}
+
+	return integrate(c, square, step) - math.Pow(integrate(c, X, step), 2)
+}
+
+// --- }}}
+
+// --- Quadrature {{{
+
+// tail is the probability mass neglected in each infinite tail of a
+// continuous distribution when integrating over its support
+const tail = 1e-12
+
+// integrate approximates ∫ f(x)c(x) dx over the support of c, by the
+// midpoint rule with intervals of width (at most) step
+func integrate(c ContinuousDistribution, f func(float64) float64, step float64) float64 {
+	assert(step > 0, "quadrature step must be positive")
+
+	lo, hi := bounds(c)
+	if lo >= hi {
+		return 0
+	}
+
+	n := math.Ceil((hi - lo) / step)
+	h := (hi - lo) / n
+
+	sum := 0.0
+	for i := 0.0; i < n; i++ {
+		x := lo + (i+0.5)*h
+		sum += f(x) * c.Density(x)
+	}
+
+	return sum * h
+}
+
+// bounds computes finite bounds for integrating over the support of c,
+// truncating any infinite bound where the mass beyond it is below tail
+func bounds(c ContinuousDistribution) (lo, hi float64) {
+	lo, hi = c.Support()
+
+	lower, upper := math.IsInf(lo, -1), math.IsInf(hi, 1)
+	if !lower && !upper {
+		return lo, hi
+	}
+
+	// widen a window until it contains all but the tails
+	a, b := lo, hi
+	for w := 1.0; ; w *= 2 {
+		assert(!math.IsInf(w, 1), "could not bound support")
+
+		if lower {
+			a = -w
+		}
+
+		if upper {
+			b = w
+		}
+
+		if c.CDF(a) <= tail && 1-c.CDF(b) <= tail {
+			break
+		}
+	}
+
+	// then tighten it, by bisection
+	if lower {
+		lo = bisect(func(x float64) bool { return c.CDF(x) > tail }, a, b)
+	}
+
+	if upper {
+		hi = bisect(func(x float64) bool { return 1-c.CDF(x) <= tail }, a, b)
+	}
+
+	return lo, hi
+}
+
+// bisect finds the point on the interval [a, b] where the monotone
+// predicate, pred, becomes true
+func bisect(pred func(float64) bool, a, b float64) float64 {
+	for i := 0; i < 100 && a < b; i++ {
+		m := a + (b-a)/2
+
+		if pred(m) {
+			b = m
+		} else {
+			a = m
+		}
+	}
+
+	return b
+}
+
+// --- }}}
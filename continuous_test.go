@@ -0,0 +1,60 @@
+package prob
+
+import (
+	"math"
+	"testing"
+)
+
+// identity is the random variable of a real valued outcome
+func identity(x float64) float64 {
+	return x
+}
+
+func TestNormal(t *testing.T) {
+	n := Normal(2, 3)
+
+	if c := n.CDF(2); !equiv(c, 0.5) {
+		t.Errorf("CDF(μ) = %f, want 0.5", c)
+	}
+
+	if lo, hi := n.Support(); !math.IsInf(lo, -1) || !math.IsInf(hi, 1) {
+		t.Errorf("Support() = (%f, %f), want (-Inf, +Inf)", lo, hi)
+	}
+
+	one := func(float64) float64 { return 1 }
+	if total := ContinuousExpectation(n, one, 0.01); !equiv(total, 1) {
+		t.Errorf("∫ f(x) dx = %f, want 1", total)
+	}
+
+	if mean := ContinuousExpectation(n, identity, 0.01); !equiv(mean, 2) {
+		t.Errorf("E[X] = %f, want 2", mean)
+	}
+
+	if variance := ContinuousVariance(n, identity, 0.01); math.Abs(variance-9) > 1e-4 {
+		t.Errorf("Var(X) = %f, want 9", variance)
+	}
+}
+
+func TestComposeContinuous(t *testing.T) {
+	p, q := Normal(-1, 1), Normal(4, 2)
+	c := ComposeContinuous(p, q, 0.3)
+
+	prev := 0.0
+	for x := -20.0; x <= 20; x += 0.25 {
+		cdf := c.CDF(x)
+
+		if cdf < prev || cdf < 0 || cdf > 1 {
+			t.Fatalf("CDF(%f) = %f, not monotone on [0, 1] after %f", x, cdf, prev)
+		}
+
+		prev = cdf
+	}
+
+	if lo, hi := c.CDF(-50), c.CDF(50); !equiv(lo, 0) || !equiv(hi, 1) {
+		t.Errorf("CDF(-50), CDF(50) = %f, %f, want 0, 1", lo, hi)
+	}
+
+	if mean := ContinuousExpectation(c, identity, 0.01); !equiv(mean, 0.3*-1+0.7*4) {
+		t.Errorf("E[X] = %f, want %f", mean, 0.3*-1+0.7*4)
+	}
+}
@@ -1,8 +1,12 @@
 package prob
 
 import (
+	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
+	"sort"
+	"sync"
 
 	"github.com/nlandolfi/set"
 )
@@ -83,8 +87,47 @@ func (p Probability) Valid() bool {
 	return p >= 0 && p <= 1
 }
 
+// Clamp restricts a Probability to the interval [0, 1], correcting
+// for floating point drift outside of it.
+func (p Probability) Clamp() Probability {
+	return Probability(math.Max(float64(Impossible), math.Min(float64(Certain), float64(p))))
+}
+
+// Add computes the sum of two probabilities, clamped to [0, 1]
+func (p Probability) Add(q Probability) Probability {
+	return (p + q).Clamp()
+}
+
+// Mul computes the product of two probabilities, clamped to [0, 1]
+func (p Probability) Mul(q Probability) Probability {
+	return (p * q).Clamp()
+}
+
 // epsilon is the acceptable floating point error
-const epsilon = 0.00001
+var epsilon = 0.00001
+
+// Epsilon returns the tolerance within which the package considers
+// two floating point values equivalent, e.g., when determining
+// whether a distribution is FullySupported.
+func Epsilon() float64 {
+	return epsilon
+}
+
+// SetEpsilon sets the tolerance within which the package considers
+// two floating point values equivalent. The default is 0.00001.
+//
+// A smaller epsilon makes comparisons stricter, which suits high
+// precision work, or very small probabilities, but rejects results
+// with accumulated rounding error; e.g., a distribution built from
+// many small masses may no longer be FullySupported. A larger epsilon
+// is more forgiving, but treats genuinely distinct values as equal.
+//
+// SetEpsilon affects the whole package, so it should be called
+// once, before any other use; it is not safe for concurrent use.
+func SetEpsilon(e float64) {
+	assert(e > 0, "epsilon must be positive")
+	epsilon = e
+}
 
 // equiv determines whether two float64s are equivalent to each
 // other with respect to epsilon
@@ -120,6 +163,113 @@ func NewUniformDiscrete(domain set.Interface) DiscreteDistribution {
 	return d
 }
 
+// NewDegenerate constructs a discrete distribution over the set
+// domain, which assigns probability 1 to the outcome o, and so
+// probability 0 to every other element of the domain. See Degenerate.
+func NewDegenerate(o Outcome, domain set.Interface) DiscreteDistribution {
+	assert(domain.Contains(o), "outcome not in domain")
+
+	return newDistribution(domain, map[Outcome]Probability{o: Certain})
+}
+
+// NewCategorical constructs a discrete distribution over the given
+// outcomes, assigning each outcome a probability proportional to its
+// weight. The weights need not sum to 1, as they are normalized, but
+// must be non-negative and not all zero.
+//
+// The domain of the distribution is the set of outcomes.
+func NewCategorical(outcomes Outcomes, weights []float64) DiscreteDistribution {
+	assert(len(outcomes) == len(weights), "outcomes and weights differ in length")
+
+	total := 0.0
+	for _, w := range weights {
+		assert(w >= 0, "negative weight")
+		total += w
+	}
+
+	assert(total > 0, "weights sum to zero")
+
+	masses := make(map[Outcome]Probability)
+	for i, o := range outcomes {
+		masses[o] += Probability(weights[i] / total)
+	}
+
+	return newDistribution(set.With(outcomes), masses)
+}
+
+// Empirical constructs the empirical distribution of the observed
+// samples. Each distinct outcome is assigned a probability equal to
+// the fraction of the samples it accounts for.
+//
+// The domain of the distribution is the set of observed outcomes.
+func Empirical(samples Outcomes) DiscreteDistribution {
+	assert(len(samples) > 0, "no samples")
+
+	weights := make([]float64, len(samples))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return NewCategorical(samples, weights)
+}
+
+// NewFromCounts constructs a discrete distribution over the set domain,
+// assigning each outcome a probability equal to its fraction of the
+// total count. Unlike Empirical, the domain is explicit, so elements
+// without counts remain in the domain, with probability Impossible.
+func NewFromCounts(counts map[Outcome]int, domain set.Interface) DiscreteDistribution {
+	total := 0
+	for o, c := range counts {
+		assert(domain.Contains(o), "outcome not in domain")
+		assert(c >= 0, "negative count")
+		total += c
+	}
+
+	assert(total > 0, "counts sum to zero")
+
+	masses := make(map[Outcome]Probability)
+	for o, c := range counts {
+		masses[o] = Probability(float64(c) / float64(total))
+	}
+
+	return newDistribution(domain, masses)
+}
+
+// Clone constructs a copy of the distribution d, sharing its domain,
+// but with its own outcomes and support. So, adding outcomes to the
+// clone does not affect d.
+func Clone(d DiscreteDistribution) DiscreteDistribution {
+	masses := make(map[Outcome]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		masses[o] = d.ProbabilityOf(o)
+	}
+
+	return newDistribution(d.Domain(), masses)
+}
+
+// newDistribution constructs a distribution over the domain d
+// directly from a map of masses, bypassing the incremental checks
+// of AddOutcome. Outcomes without mass are not recorded.
+func newDistribution(d set.AbstractInterface, masses map[Outcome]Probability) *distribution {
+	n := &distribution{
+		domain:   d,
+		outcomes: set.New(),
+		support:  make(map[Outcome]Probability, len(masses)),
+	}
+
+	for o, p := range masses {
+		if p == Impossible {
+			continue
+		}
+
+		n.outcomes.Add(o)
+		n.support[o] = p
+	}
+
+	return n
+}
+
 // distribution structure serves as an implementation
 // of the DiscreteDistribution (and therefore implicitly
 // Distribution) interfaces
@@ -142,10 +292,20 @@ func (d *distribution) Support() Outcomes {
 }
 
 func (d *distribution) AddOutcome(o Outcome, p Probability) {
-	assert(!equiv(float64(Support(d)), 1.0), "distribution already fully supported")
-	assert((float64(Support(d)+p) < 1.0+epsilon), "adding outcome would over-support")
 	assert(p.Valid(), "invalid probability")
-	assert(!equiv(float64(p), 0), "probability zero")
+
+	// an impossible outcome already has probability zero, by
+	// virtue of being in the domain, so there is nothing to record
+	if p == Impossible {
+		return
+	}
+
+	if checks && equiv(float64(Support(d)), 1.0) {
+		panic("distribution already fully supported")
+	}
+	if checks && float64(Support(d)+p) >= 1.0+epsilon {
+		panic("adding outcome would over-support")
+	}
 
 	d.outcomes.Add(o)
 	d.support[o] = p
@@ -165,6 +325,17 @@ func (d *distribution) ProbabilityOf(o Outcome) Probability {
 	}
 }
 
+// ProbabilityOfSafe returns the probability of the outcome o, and
+// whether o is in the domain of d. Unlike ProbabilityOf, which panics
+// for an outcome outside the domain, it returns (Impossible, false).
+func (d *distribution) ProbabilityOfSafe(o Outcome) (Probability, bool) {
+	if p, ok := d.support[o]; ok {
+		return p, true
+	}
+
+	return Impossible, d.domain.Contains(o)
+}
+
 // --- }}}
 
 // --- Distribution Properties {{{
@@ -186,6 +357,30 @@ func Support(d Distribution) Probability {
 	return p
 }
 
+// ProbabilityOfSafe returns the probability of the outcome o under
+// the distribution d, and whether o is in the domain of d. Unlike
+// ProbabilityOf, which may panic for an outcome outside the domain,
+// it returns (Impossible, false).
+//
+// If d has a ProbabilityOfSafe method, as the distributions of this
+// package do, it is used. Otherwise the domain of d is checked before
+// calling ProbabilityOf. The method is deliberately not added to the
+// Distribution or DiscreteDistribution interfaces, as that would break
+// their implementations outside this package.
+func ProbabilityOfSafe(d Distribution, o Outcome) (Probability, bool) {
+	if s, ok := d.(interface {
+		ProbabilityOfSafe(Outcome) (Probability, bool)
+	}); ok {
+		return s.ProbabilityOfSafe(o)
+	}
+
+	if !d.Domain().Contains(o) {
+		return Impossible, false
+	}
+
+	return d.ProbabilityOf(o), true
+}
+
 // FullySupported checks that a Distribution has assigned all
 // of it's probability mass.
 //
@@ -194,6 +389,23 @@ func FullySupported(d Distribution) bool {
 	return equiv(float64(Support(d)), float64(Certain))
 }
 
+// Normalize rescales, in place, the probability of every outcome of
+// the distribution d by 1/Support(d), so that d becomes fully
+// supported. The support of d must be non-zero.
+//
+// Note: d must have been constructed by this package.
+func Normalize(d DiscreteDistribution) {
+	n, ok := d.(*distribution)
+	assert(ok, "can not normalize distribution of unknown type")
+
+	total := Support(n)
+	assert(total != Impossible, "can not normalize distribution without support")
+
+	for o := range n.support {
+		n.support[o] /= total
+	}
+}
+
 // The cardinality of a discrete distribution is the number of
 // potential outcomes
 func Cardinality(d DiscreteDistribution) uint {
@@ -208,6 +420,95 @@ func Degenerate(d DiscreteDistribution) bool {
 	return Cardinality(d) == 1 && FullySupported(d)
 }
 
+// Mode computes the most probable outcomes of a DiscreteDistribution.
+// Modes need not be unique, so all outcomes whose probability is the
+// maximum, within epsilon, are returned.
+func Mode(d DiscreteDistribution) Outcomes {
+	var m modes
+
+	for _, o := range sorted(d.Outcomes().Elements()) {
+		m.add(o, d.ProbabilityOf(o))
+	}
+
+	return m.outcomes
+}
+
+// modes tracks the most probable of the outcomes added to it, where
+// probabilities within epsilon of each other are tied
+type modes struct {
+	outcomes Outcomes
+	max      Probability
+}
+
+// add considers the outcome o, which has probability p
+func (m *modes) add(o Outcome, p Probability) {
+	switch {
+	case equiv(float64(p), float64(m.max)):
+		m.outcomes = append(m.outcomes, o)
+	case p > m.max:
+		m.max = p
+		m.outcomes = Outcomes{o}
+	}
+}
+
+// SupportContains determines whether the support of the distribution
+// outer covers the support of inner. That is, whether every outcome
+// with positive probability under inner also has positive probability
+// under outer.
+//
+// For example, an importance sampling proposal must cover its target.
+func SupportContains(outer, inner Distribution) bool {
+	for _, o := range inner.Outcomes().Elements() {
+		if inner.ProbabilityOf(o) == Impossible {
+			continue
+		}
+
+		if !outer.Domain().Contains(o) || outer.ProbabilityOf(o) == Impossible {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DistributionsEqual determines whether the distributions p and q
+// are equal: their domains are equivalent, and every outcome has the
+// same probability under both, within epsilon.
+func DistributionsEqual(p, q Distribution) bool {
+	if !equivalentDomains(p, q) {
+		return false
+	}
+
+	for _, o := range set.Union(p.Outcomes(), q.Outcomes()).Elements() {
+		if !p.Domain().Contains(o) || !q.Domain().Contains(o) {
+			return false
+		}
+
+		if !equiv(float64(p.ProbabilityOf(o)), float64(q.ProbabilityOf(o))) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equivalentDomains determines whether the distributions p and q
+// have equivalent domains. Domains which can not be enumerated can
+// not be compared, and are assumed to be equivalent.
+func equivalentDomains(p, q Distribution) bool {
+	pd, ok := p.Domain().(set.Interface)
+	if !ok {
+		return true
+	}
+
+	qd, ok := q.Domain().(set.Interface)
+	if !ok {
+		return true
+	}
+
+	return set.Equivalent(pd, qd)
+}
+
 // --- }}}
 
 // --- Events {{{
@@ -224,6 +525,90 @@ func ProbabilityOf(d Distribution, A Event) Probability {
 	return sum
 }
 
+// ProbabilityOfValue calculates the probability that the random
+// variable X takes on the value, given a Distribution, d
+//
+// Equivalently: P(X = value), the probability of the event
+// { o ∈ Ω | X(o) = value }
+func ProbabilityOfValue(d Distribution, X RandomVariable, value float64) Probability {
+	sum := Impossible
+
+	for _, o := range d.Outcomes().Elements() {
+		if equiv(X(o), value) {
+			sum += d.ProbabilityOf(o)
+		}
+	}
+
+	return sum
+}
+
+// ConditionalProbability calculates the probability of the event A,
+// given that the event B occurs, under the Distribution d. The event
+// B must have non-zero probability.
+//
+// Recall: P(A | B) = P(A ∩ B)/P(B)
+func ConditionalProbability(d Distribution, A, B Event) Probability {
+	pB := ProbabilityOf(d, B)
+	assert(pB != Impossible, "conditioning on an impossible event")
+
+	return ProbabilityOf(d, set.Intersection(A, B)) / pB
+}
+
+// Complement computes the complement of the event A, with respect to
+// the domain of the distribution d, which must be enumerable.
+//
+// Recall: Aᶜ = Ω \ A
+func Complement(d Distribution, A Event) Event {
+	domain, ok := d.Domain().(set.Interface)
+	assert(ok, "domain is not enumerable")
+
+	complement := set.New()
+	for _, o := range domain.Elements() {
+		if !A.Contains(o) {
+			complement.Add(o)
+		}
+	}
+
+	return complement
+}
+
+// ProbabilityOfComplement calculates the probability of the complement
+// of an event, A, given a Distribution, d. For a fully supported
+// distribution, P(Aᶜ) = 1 - P(A).
+func ProbabilityOfComplement(d Distribution, A Event) Probability {
+	return ProbabilityOf(d, Complement(d, A))
+}
+
+// maxPowerSetCardinality bounds the cardinality of a set whose power
+// set PowerSet will enumerate
+const maxPowerSetCardinality = 20
+
+// PowerSet enumerates every event of the outcome space omega. That is,
+// all 2^n subsets of omega, including the empty set and omega itself.
+//
+// Note: the number of events grows exponentially, so PowerSet panics
+// for outcome spaces of more than 20 outcomes.
+func PowerSet(omega set.Interface) []Event {
+	elements := sorted(omega.Elements())
+	assert(len(elements) <= maxPowerSetCardinality, "outcome space too large to enumerate power set")
+
+	events := make([]Event, 0, 1<<uint(len(elements)))
+
+	for mask := 0; mask < 1<<uint(len(elements)); mask++ {
+		A := set.New()
+
+		for i, e := range elements {
+			if mask&(1<<uint(i)) != 0 {
+				A.Add(e)
+			}
+		}
+
+		events = append(events, A)
+	}
+
+	return events
+}
+
 // IndependentEvents determines whether A and B are independent
 // under the distribution d.
 //
@@ -248,6 +633,101 @@ func Moment(d Distribution, X RandomVariable, n int) float64 {
 	return Expectation(d, moment)
 }
 
+// CentralMoment calculates the nth central moment of a random variable
+//
+// Recall: the nth central moment of a random variable X over a
+// distribution d is the expectation of (X - E[X])^n
+func CentralMoment(d Distribution, X RandomVariable, n int) float64 {
+	mean := Expectation(d, X)
+
+	moment := func(o Outcome) float64 {
+		return math.Pow(X(o)-mean, float64(n))
+	}
+
+	return Expectation(d, moment)
+}
+
+// Skewness calculates the skewness of a random variable, the third
+// standardized moment. Symmetric distributions have zero skewness.
+//
+// Recall: Skew(X) = E[(X - E[X])^3] / σ^3
+func Skewness(d Distribution, X RandomVariable) float64 {
+	return CentralMoment(d, X, 3) / math.Pow(CentralMoment(d, X, 2), 1.5)
+}
+
+// Kurtosis calculates the kurtosis of a random variable, the fourth
+// standardized moment. Normal distributions have kurtosis 3.
+//
+// Recall: Kurt(X) = E[(X - E[X])^4] / σ^4
+func Kurtosis(d Distribution, X RandomVariable) float64 {
+	return CentralMoment(d, X, 4) / math.Pow(CentralMoment(d, X, 2), 2)
+}
+
+// Memoize constructs a random variable equivalent to X, which
+// evaluates X at most once per distinct outcome. This is useful when
+// X is expensive, as Moment, Variance, etc. evaluate it repeatedly.
+//
+// The memoized random variable is safe for concurrent use.
+func Memoize(X RandomVariable) RandomVariable {
+	var mu sync.Mutex
+	cache := make(map[Outcome]float64)
+
+	return func(o Outcome) float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		x, ok := cache[o]
+		if !ok {
+			x = X(o)
+			cache[o] = x
+		}
+
+		return x
+	}
+}
+
+// Add constructs the random variable X + Y
+func Add(X, Y RandomVariable) RandomVariable {
+	return func(o Outcome) float64 {
+		return X(o) + Y(o)
+	}
+}
+
+// Scale constructs the random variable cX
+func Scale(c float64, X RandomVariable) RandomVariable {
+	return func(o Outcome) float64 {
+		return c * X(o)
+	}
+}
+
+// Mul constructs the random variable XY
+func Mul(X, Y RandomVariable) RandomVariable {
+	return func(o Outcome) float64 {
+		return X(o) * Y(o)
+	}
+}
+
+// Apply constructs the random variable f(X)
+func Apply(f func(float64) float64, X RandomVariable) RandomVariable {
+	return func(o Outcome) float64 {
+		return f(X(o))
+	}
+}
+
+// Indicator constructs the random variable 1_A, which is 1 on the
+// outcomes in the event A, and 0 otherwise.
+//
+// Recall: E[1_A] = P(A)
+func Indicator(A Event) RandomVariable {
+	return func(o Outcome) float64 {
+		if A.Contains(o) {
+			return 1
+		}
+
+		return 0
+	}
+}
+
 // Expectation computes the expected value of a random variable,
 // X over a distribution d
 func Expectation(d Distribution, X RandomVariable) float64 {
@@ -260,6 +740,90 @@ func Expectation(d Distribution, X RandomVariable) float64 {
 	return exp
 }
 
+// ExpectationVector computes the expected value of each of the random
+// variables Xs over a distribution d, in a single pass over the
+// outcomes of d. This is faster than calling Expectation per variable.
+func ExpectationVector(d Distribution, Xs []RandomVariable) []float64 {
+	exps := make([]float64, len(Xs))
+
+	for _, o := range d.Outcomes().Elements() {
+		p := float64(d.ProbabilityOf(o))
+
+		for i, X := range Xs {
+			exps[i] += X(o) * p
+		}
+	}
+
+	return exps
+}
+
+// ExpectationOfProduct computes the expected value of the product of
+// the random variables Xs, over a distribution d, in a single pass
+// over the outcomes of d.
+//
+// Recall: E[X_1 X_2 ... X_k] = Σ X_1(o)X_2(o)...X_k(o)P(o)
+func ExpectationOfProduct(d Distribution, Xs ...RandomVariable) float64 {
+	exp := 0.0
+
+	for _, o := range d.Outcomes().Elements() {
+		x := float64(d.ProbabilityOf(o))
+
+		for _, X := range Xs {
+			x *= X(o)
+		}
+
+		exp += x
+	}
+
+	return exp
+}
+
+// ExpectationChecked computes the expected value of a random variable,
+// X over a distribution d, as Expectation does. But, if X is NaN or
+// infinite at any outcome, it returns an error naming that outcome.
+func ExpectationChecked(d Distribution, X RandomVariable) (float64, error) {
+	exp := 0.0
+
+	for _, o := range d.Outcomes().Elements() {
+		x := X(o)
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0, fmt.Errorf("prob: random variable is %v at outcome %v", x, o)
+		}
+
+		exp += x * float64(d.ProbabilityOf(o))
+	}
+
+	return exp, nil
+}
+
+// DefaultPrecision is the precision, in bits, of the accumulator
+// used by ExpectationPrecise
+const DefaultPrecision = 256
+
+// ExpectationPrecise computes the expected value of a random variable,
+// X over a distribution d, accumulating the sum in a big.Float of
+// DefaultPrecision bits. Unlike Expectation, the result does not
+// drift when summing many terms of widely varying magnitude.
+func ExpectationPrecise(d Distribution, X RandomVariable) *big.Float {
+	return ExpectationPreciseWith(d, X, DefaultPrecision)
+}
+
+// ExpectationPreciseWith computes the expected value of a random
+// variable, X over a distribution d, accumulating the sum in a
+// big.Float of prec bits.
+func ExpectationPreciseWith(d Distribution, X RandomVariable, prec uint) *big.Float {
+	exp := new(big.Float).SetPrec(prec)
+	term := new(big.Float).SetPrec(prec)
+
+	for _, o := range d.Outcomes().Elements() {
+		term.SetFloat64(X(o))
+		term.Mul(term, big.NewFloat(float64(d.ProbabilityOf(o))))
+		exp.Add(exp, term)
+	}
+
+	return exp
+}
+
 // Variance computes the variance of a random variable, X,
 // over a distribution d
 //
@@ -268,20 +832,393 @@ func Variance(d Distribution, X RandomVariable) float64 {
 	return Moment(d, X, 2) - math.Pow(Moment(d, X, 1), 2.0)
 }
 
+// ExpectationSlices computes the expected value of a random variable,
+// given as parallel slices of its values and their probabilities.
+// The probabilities must sum to 1.
+func ExpectationSlices(values []float64, probs []Probability) float64 {
+	assertSlices(values, probs)
+
+	exp := 0.0
+
+	for i := range values {
+		exp += values[i] * float64(probs[i])
+	}
+
+	return exp
+}
+
+// VarianceSlices computes the variance of a random variable, given
+// as parallel slices of its values and their probabilities.
+// The probabilities must sum to 1.
+//
+// Recall: Var(X) = E(X^2) - E(X)^2
+func VarianceSlices(values []float64, probs []Probability) float64 {
+	assertSlices(values, probs)
+
+	exp, sq := 0.0, 0.0
+
+	for i := range values {
+		exp += values[i] * float64(probs[i])
+		sq += values[i] * values[i] * float64(probs[i])
+	}
+
+	return sq - exp*exp
+}
+
+// assertSlices checks that values and probs are parallel slices
+// defining a fully supported random variable
+func assertSlices(values []float64, probs []Probability) {
+	assert(len(values) == len(probs), "values and probabilities differ in length")
+
+	sum := Impossible
+	for _, p := range probs {
+		assert(p.Valid(), "invalid probability")
+		sum += p
+	}
+
+	assert(equiv(float64(sum), float64(Certain)), "probabilities do not sum to 1")
+}
+
 // Covariance computes the covariance of the random variables X and Y,
 // over a distribution d.
 //
 // Recall: Cov(X, Y) = E(XY) - E(X)E(Y)
 func Covariance(d Distribution, X, Y RandomVariable) float64 {
-	return Expectation(d, func(o Outcome) float64 { return X(o) * Y(o) }) - Expectation(d, X)*Expectation(d, Y)
+	return ExpectationOfProduct(d, X, Y) - Expectation(d, X)*Expectation(d, Y)
+}
+
+// CovarianceMatrix computes the covariance of each pair of the random
+// variables Xs, over a distribution d, in a single pass over the
+// outcomes of d. The matrix is symmetric, with the variance of each
+// random variable on its diagonal.
+//
+// Recall: Σ_ij = Cov(X_i, X_j) = E(X_i X_j) - E(X_i)E(X_j)
+func CovarianceMatrix(d Distribution, Xs []RandomVariable) [][]float64 {
+	n := len(Xs)
+	means := make([]float64, n)
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+
+	xs := make([]float64, n)
+
+	for _, o := range d.Outcomes().Elements() {
+		p := float64(d.ProbabilityOf(o))
+
+		for i, X := range Xs {
+			xs[i] = X(o)
+			means[i] += p * xs[i]
+		}
+
+		for i := 0; i < n; i++ {
+			for j := i; j < n; j++ {
+				cov[i][j] += p * xs[i] * xs[j]
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			cov[i][j] -= means[i] * means[j]
+			cov[j][i] = cov[i][j]
+		}
+	}
+
+	return cov
+}
+
+// Correlation computes the (Pearson) correlation coefficient of the
+// random variables X and Y, over a distribution d. It lies on the
+// interval [-1, 1].
+//
+// Recall: Corr(X, Y) = Cov(X, Y) / (σ(X)σ(Y))
+//
+// If either X or Y has zero variance, the correlation is undefined
+// and reported as 0.
+func Correlation(d Distribution, X, Y RandomVariable) float64 {
+	_, corr := CovarianceAndCorrelation(d, X, Y)
+	return corr
+}
+
+// cancellation is the relative error below which a difference of
+// nearly equal floating point values, e.g., E(X^2) - E(X)^2, can not
+// be distinguished from 0
+const cancellation = 1e-12
+
+// CovarianceAndCorrelation computes both the covariance and the
+// (Pearson) correlation of the random variables X and Y, over a
+// distribution d, sharing the intermediate expectations.
+//
+// Recall: Corr(X, Y) = Cov(X, Y) / (σ(X)σ(Y))
+//
+// If either X or Y has zero variance, the correlation is undefined
+// and reported as 0.
+func CovarianceAndCorrelation(d Distribution, X, Y RandomVariable) (cov, corr float64) {
+	var ex, ey, exx, eyy, exy float64
+
+	for _, o := range d.Outcomes().Elements() {
+		p, x, y := float64(d.ProbabilityOf(o)), X(o), Y(o)
+
+		ex += p * x
+		ey += p * y
+		exx += p * x * x
+		eyy += p * y * y
+		exy += p * x * y
+	}
+
+	cov = exy - ex*ey
+
+	// the variances are differences of nearly equal terms, so they
+	// are only meaningful relative to the second moments
+	vx, vy := exx-ex*ex, eyy-ey*ey
+	if vx <= cancellation*exx || vy <= cancellation*eyy {
+		return cov, 0
+	}
+
+	return cov, math.Max(-1, math.Min(1, cov/math.Sqrt(vx*vy)))
 }
 
 // IndependentVariables determines whether two random variables X and Y are
 // independent over the distribution d.
 //
 // Recall: X ind. Y iff Cov(X, Y) = 0
+//
+// The covariance is compared to 0 within epsilon, as it is rarely
+// exactly 0 after floating point arithmetic.
 func IndependentVariables(d Distribution, X, Y RandomVariable) bool {
-	return Covariance(d, X, Y) == 0
+	return equiv(Covariance(d, X, Y), 0)
+}
+
+// CDF computes the cumulative distribution function of the random
+// variable X over the distribution d, evaluated at t. That is, the
+// probability that X ≤ t.
+func CDF(d DiscreteDistribution, X RandomVariable, t float64) Probability {
+	values, probs := ValueTable(d, X)
+
+	cum := Impossible
+	for i, v := range values {
+		if v > t {
+			break
+		}
+
+		cum += probs[i]
+	}
+
+	return cum
+}
+
+// Quantile computes the qth quantile of the random variable X over
+// the distribution d. That is, the smallest value v such that
+// P(X ≤ v) ≥ q, within epsilon.
+func Quantile(d DiscreteDistribution, X RandomVariable, q Probability) float64 {
+	assert(q.Valid(), "invalid probability")
+
+	values, probs := ValueTable(d, X)
+	assert(len(values) > 0, "distribution has no outcomes")
+
+	cum := Impossible
+	for i, v := range values {
+		cum += probs[i]
+
+		if cum >= q || equiv(float64(cum), float64(q)) {
+			return v
+		}
+	}
+
+	return values[len(values)-1]
+}
+
+// Median computes the median of the random variable X over the
+// distribution d. That is, the 0.5 quantile of X.
+//
+// If the CDF of X is exactly 0.5 (within epsilon) at a value, the
+// median lies between that value and the next, and their average is
+// returned. So the median of a uniform die over {1, 2, 3, 4} is 2.5.
+func Median(d DiscreteDistribution, X RandomVariable) float64 {
+	values, probs := ValueTable(d, X)
+	assert(len(values) > 0, "distribution has no outcomes")
+
+	cum := Impossible
+	for i, v := range values {
+		cum += probs[i]
+
+		if equiv(float64(cum), 0.5) && i+1 < len(values) {
+			return (v + values[i+1]) / 2
+		}
+
+		if cum >= 0.5 {
+			return v
+		}
+	}
+
+	return values[len(values)-1]
+}
+
+// SpearmanCorrelation computes the Spearman rank correlation of the
+// random variables X and Y, over a distribution d.
+//
+// The Spearman correlation is the Pearson correlation of the ranks of
+// X and Y. It measures monotonic, rather than only linear, association.
+// So, if Y is any increasing function of X, the Spearman correlation is 1.
+//
+// The rank of a value x is weighted by probability, and ties share the
+// mid-rank: P(X < x) + P(X = x)/2
+func SpearmanCorrelation(d Distribution, X, Y RandomVariable) float64 {
+	return Correlation(d, midRank(d, X), midRank(d, Y))
+}
+
+// midRank constructs the random variable mapping an outcome, o, to the
+// mid-rank of X(o) under the distribution d
+func midRank(d Distribution, X RandomVariable) RandomVariable {
+	values, probs := ValueTable(d, X)
+	ranks := make([]float64, len(values))
+
+	below := 0.0
+	for i, p := range probs {
+		ranks[i] = below + float64(p)/2
+		below += float64(p)
+	}
+
+	return func(o Outcome) float64 {
+		x := X(o)
+		return ranks[sort.Search(len(values), func(i int) bool { return values[i] > x-epsilon })]
+	}
+}
+
+// ValueTable tabulates the distinct values X takes on the outcomes of
+// d, sorted ascending, alongside the total probability of each value.
+// Outcomes mapping to values equivalent within epsilon are merged.
+//
+// This is the data for a histogram, or plot of the CDF, of X.
+func ValueTable(d Distribution, X RandomVariable) ([]float64, []Probability) {
+	type point struct {
+		x float64
+		p Probability
+	}
+
+	points := make([]point, 0, d.Outcomes().Cardinality())
+	for _, o := range d.Outcomes().Elements() {
+		points = append(points, point{X(o), d.ProbabilityOf(o)})
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].x < points[j].x
+	})
+
+	values := make([]float64, 0, len(points))
+	probs := make([]Probability, 0, len(points))
+
+	for _, pt := range points {
+		if n := len(values); n > 0 && equiv(values[n-1], pt.x) {
+			probs[n-1] += pt.p
+			continue
+		}
+
+		values = append(values, pt.x)
+		probs = append(probs, pt.p)
+	}
+
+	return values, probs
+}
+
+// --- }}}
+
+// --- Conditioning {{{
+
+// Conditional computes the distribution of d, conditioned on the
+// event A. Outcomes outside of A become impossible, and the
+// probabilities of outcomes in A are renormalized by P(A).
+//
+// Recall: P(o | A) = P(o)/P(A), for o ∈ A
+func Conditional(d DiscreteDistribution, A Event) DiscreteDistribution {
+	pA := ProbabilityOf(d, A)
+	assert(pA != Impossible, "conditioning on an impossible event")
+
+	masses := make(map[Outcome]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		if A.Contains(o) {
+			masses[o] = d.ProbabilityOf(o) / pA
+		}
+	}
+
+	return newDistribution(d.Domain(), masses)
+}
+
+// Truncate computes the distribution of d, restricted to the outcomes
+// which satisfy the predicate keep, and renormalized. It is Conditional
+// on the event { o ∈ Ω | keep(o) }, defined by a predicate rather than
+// an explicit set.
+//
+// Some outcome with non-zero probability must satisfy keep.
+func Truncate(d DiscreteDistribution, keep func(Outcome) bool) DiscreteDistribution {
+	A := set.New()
+
+	for _, o := range d.Outcomes().Elements() {
+		if keep(o) {
+			A.Add(o)
+		}
+	}
+
+	assert(A.Cardinality() > 0, "no outcome satisfies predicate")
+
+	return Conditional(d, A)
+}
+
+// ConditionalExpectation computes the expected value of a random
+// variable, X, over a distribution d, conditioned on the event A.
+// The event must have non-zero probability.
+//
+// Recall: E[X | A] = Σ_{o ∈ A} X(o)P(o) / P(A)
+func ConditionalExpectation(d DiscreteDistribution, X RandomVariable, A Event) float64 {
+	return Expectation(Conditional(d, A), X)
+}
+
+// --- }}}
+
+// --- Transformation {{{
+
+// Transform computes the distribution of f(o), where the outcome o
+// is distributed according to d. The domain of the result is the
+// image of the domain of d under f.
+//
+// Recall: P(f(o) = b) = Σ_{a | f(a) = b} P(a)
+func Transform(d DiscreteDistribution, f func(Outcome) Outcome) DiscreteDistribution {
+	masses := make(map[Outcome]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		masses[f(o)] += d.ProbabilityOf(o)
+	}
+
+	return newDistribution(image(d, f), masses)
+}
+
+// PushForward computes the distribution of the random variable X,
+// whose outcomes are the (float64) values X takes. The mass of each
+// value is aggregated over its preimage. See Transform.
+//
+// Recall: P_X(x) = P({ o ∈ Ω | X(o) = x })
+func PushForward(d DiscreteDistribution, X RandomVariable) DiscreteDistribution {
+	return Transform(d, func(o Outcome) Outcome {
+		return X(o)
+	})
+}
+
+// image computes the image of the domain of d under f. If the domain
+// can not be enumerated, the image of the outcomes of d is used.
+func image(d Distribution, f func(Outcome) Outcome) set.Interface {
+	elements := d.Outcomes().Elements()
+	if domain, ok := d.Domain().(set.Interface); ok {
+		elements = domain.Elements()
+	}
+
+	img := set.New()
+	for _, e := range elements {
+		img.Add(f(e))
+	}
+
+	return img
 }
 
 // --- }}}
@@ -295,14 +1232,20 @@ func IndependentVariables(d Distribution, X, Y RandomVariable) bool {
 //
 // for o ∈ p.Domain() intersect q.Domain(); P(x in n) is alpha*P(x in p) + (1-alpha)P(x in q)
 func Compose(p, q DiscreteDistribution, alpha Probability) DiscreteDistribution {
-	assert(FullySupported(p), "first distribution is not fully supported")
-	assert(FullySupported(q), "second distribution is not fully supported")
-	//assert(set.Equivalent(p.Domain(), q.Domain()), "domains of both distributions must be equivalent")
+	if checks && !FullySupported(p) {
+		panic("first distribution is not fully supported")
+	}
+	if checks && !FullySupported(q) {
+		panic("second distribution is not fully supported")
+	}
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
 
 	n := NewDiscreteDistribution(p.Domain())
 
-	for _, o := range n.Outcomes().Elements() {
-		cp := alpha*p.ProbabilityOf(o) + (1-alpha)*q.ProbabilityOf(o)
+	for _, o := range set.Union(p.Outcomes(), q.Outcomes()).Elements() {
+		cp := alpha.Mul(p.ProbabilityOf(o)).Add((1 - alpha).Mul(q.ProbabilityOf(o)))
 		if cp == Impossible {
 			continue // don't bother supporting
 		}
@@ -313,6 +1256,118 @@ func Compose(p, q DiscreteDistribution, alpha Probability) DiscreteDistribution
 	return n
 }
 
+// Mixture constructs the convex combination of the distributions
+// components, taking on each outcome with probability
+// Σ_i weights[i]*P_i(o). The weights must be non-negative and sum to
+// 1, and the domains of the components must be equivalent.
+//
+// Mixture generalizes Compose to more than two distributions.
+func Mixture(components []DiscreteDistribution, weights []Probability) DiscreteDistribution {
+	assert(len(components) > 0, "no components")
+	assert(len(components) == len(weights), "components and weights differ in length")
+
+	total := Impossible
+	for i, w := range weights {
+		assert(w.Valid(), "invalid weight")
+		if checks && !FullySupported(components[i]) {
+			panic("component is not fully supported")
+		}
+		if checks && !equivalentDomains(components[0], components[i]) {
+			panic("domains of all components must be equivalent")
+		}
+
+		total += w
+	}
+
+	assert(equiv(float64(total), float64(Certain)), "weights do not sum to 1")
+
+	masses := make(map[Outcome]Probability)
+
+	for i, c := range components {
+		for _, o := range c.Outcomes().Elements() {
+			masses[o] += weights[i] * c.ProbabilityOf(o)
+		}
+	}
+
+	return newDistribution(components[0].Domain(), masses)
+}
+
+// Compound constructs the distribution of the random sum T_1 + ... + T_N,
+// where N is distributed according to count, and each T_i is distributed
+// according to term, independently. Both count and term must be
+// distributions over integer outcomes.
+//
+// The distribution is computed by conditioning on N, and convolving the
+// term distribution N times. Counts above maxCount are neglected, and
+// the result renormalized over the counts considered.
+//
+// Recall: E[Σ T_i] = E[N]E[T]
+func Compound(count, term DiscreteDistribution, maxCount int) DiscreteDistribution {
+	assert(maxCount >= 0, "maximum count must be non-negative")
+
+	counts := integerMasses(count)
+	terms := integerMasses(term)
+
+	// sum is the distribution of T_1 + ... + T_n
+	sum := map[int]Probability{0: Certain}
+
+	masses := make(map[Outcome]Probability)
+	considered := Impossible
+
+	for n := 0; n <= maxCount; n++ {
+		if n > 0 {
+			sum = convolve(sum, terms)
+		}
+
+		pn := counts[n]
+		if pn == Impossible {
+			continue
+		}
+
+		for s, p := range sum {
+			masses[s] += pn * p
+		}
+
+		considered += pn
+	}
+
+	assert(considered != Impossible, "count impossible below maximum")
+
+	domain := set.New()
+	for s := range masses {
+		masses[s] /= considered
+		domain.Add(s)
+	}
+
+	return newDistribution(domain, masses)
+}
+
+// integerMasses tabulates the probabilities of a distribution over
+// integer outcomes
+func integerMasses(d DiscreteDistribution) map[int]Probability {
+	masses := make(map[int]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		masses[integer(o)] = d.ProbabilityOf(o)
+	}
+
+	return masses
+}
+
+// convolve computes the distribution of the sum of two independent
+// integer random variables, distributed according to p and q
+func convolve(p, q map[int]Probability) map[int]Probability {
+	r := make(map[int]Probability)
+
+	for a, pa := range p {
+		for b, qb := range q {
+			r[a+b] += pa * qb
+		}
+	}
+
+	return r
+}
+
 // --- }}}
 
 // --- Simulation {{{
@@ -323,18 +1378,54 @@ func Compose(p, q DiscreteDistribution, alpha Probability) DiscreteDistribution
 //		s := set.WithElements(1, 2, 3)
 //		d := NewUniformDiscrete(s)
 //		Simulate(d) => 1 w.p. 1/3, 2 w.p. 1/3, 3 w.p. 1/3
+//
+// The draw is scaled by the total support of d, so outcomes are
+// chosen proportionally to their masses even when those do not sum
+// to 1, e.g., masses of 0.49 and 0.49 are each chosen w.p. 1/2. The
+// distribution must have some support.
 func Simulate(d DiscreteDistribution) Outcome {
-	assert(FullySupported(d), "discrete distribution not fully supported")
+	return simulate(d, d.Outcomes().Elements(), rand.Float64())
+}
 
-	f := Probability(rand.Float64())
+// SimulateWith simulates an experiment with the distribution defined
+// by the DiscreteDistribution, drawing from the generator r.
+//
+// When the outcomes are ints, int64s, float64s or strings, they are
+// considered in a stable order, so the same seed yields the same
+// sequence of outcomes:
+//
+//		r1, r2 := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+//		SimulateWith(d, r1) == SimulateWith(d, r2)
+//
+// Outcomes of any other type, e.g., Pairs, have no such order, so
+// they are considered in the order of d.Outcomes().
+func SimulateWith(d DiscreteDistribution, r *rand.Rand) Outcome {
+	return simulate(d, sorted(d.Outcomes().Elements()), r.Float64())
+}
+
+// simulate selects the first of the outcomes of d at which the
+// cumulative probability exceeds f ∈ [0, 1), scaled by the total support.
+//
+// The draw is scaled by the very cumulative sum it is compared
+// against, accumulated in the same order, so drift in that sum can
+// not bias the final outcome.
+func simulate(d DiscreteDistribution, outcomes Outcomes, f float64) Outcome {
+	total := Impossible
+	for _, o := range outcomes {
+		total += d.ProbabilityOf(o)
+	}
+
+	assert(total > Impossible, "discrete distribution has no support")
+
+	target := Probability(f) * total
 	p := Probability(0)
 
 	var last Outcome
-	for _, o := range d.Outcomes().Elements() {
+	for _, o := range outcomes {
 		p += d.ProbabilityOf(o)
 		last = o
 
-		if f < p {
+		if target < p {
 			return o
 		}
 	}
@@ -0,0 +1,943 @@
+package prob
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+// die constructs the distribution of a fair six-sided die
+func die() DiscreteDistribution {
+	return NewUniformDiscrete(set.WithElements(1, 2, 3, 4, 5, 6))
+}
+
+// dice constructs the joint distribution of two fair six-sided dice,
+// whose outcomes are Pairs
+func dice() DiscreteDistribution {
+	pairs := set.New()
+	masses := make(map[Outcome]Probability)
+
+	for i := 1; i <= 6; i++ {
+		for j := 1; j <= 6; j++ {
+			pairs.Add(Pair{i, j})
+			masses[Pair{i, j}] = 1.0 / 36
+		}
+	}
+
+	return newDistribution(pairs, masses)
+}
+
+// value is the random variable whose value is the (int) outcome
+func value(o Outcome) float64 {
+	return float64(o.(int))
+}
+
+func TestProbabilityOfValue(t *testing.T) {
+	d := die()
+
+	if p := ProbabilityOfValue(d, value, 3); !equiv(float64(p), 1.0/6) {
+		t.Errorf("ProbabilityOfValue(d, X, 3) = %f, want %f", p, 1.0/6)
+	}
+
+	if p := ProbabilityOfValue(d, value, 7); p != Impossible {
+		t.Errorf("ProbabilityOfValue(d, X, 7) = %f, want 0", p)
+	}
+
+	parity := func(o Outcome) float64 {
+		return float64(o.(int) % 2)
+	}
+
+	if p := ProbabilityOfValue(d, parity, 0); !equiv(float64(p), 0.5) {
+		t.Errorf("ProbabilityOfValue(d, parity, 0) = %f, want 0.5", p)
+	}
+}
+
+func TestCovarianceAndCorrelation(t *testing.T) {
+	d := die()
+	square := func(o Outcome) float64 {
+		return value(o) * value(o)
+	}
+
+	cov, corr := CovarianceAndCorrelation(d, value, square)
+
+	if want := Covariance(d, value, square); !equiv(cov, want) {
+		t.Errorf("cov = %f, want Covariance = %f", cov, want)
+	}
+
+	want := Covariance(d, value, square) / math.Sqrt(Variance(d, value)*Variance(d, square))
+	if !equiv(corr, want) {
+		t.Errorf("corr = %f, want %f", corr, want)
+	}
+}
+
+func TestCovarianceAndCorrelationSmallScale(t *testing.T) {
+	d := NewUniformDiscrete(set.WithElements(0, 1))
+	small := func(o Outcome) float64 {
+		return 0.001 * value(o)
+	}
+
+	if _, corr := CovarianceAndCorrelation(d, small, small); !equiv(corr, 1) {
+		t.Errorf("corr of X ∈ {0, 0.001} with itself = %f, want 1", corr)
+	}
+}
+
+func TestCovarianceAndCorrelationConstant(t *testing.T) {
+	d := die()
+	constant := func(o Outcome) float64 {
+		return 7
+	}
+
+	if _, corr := CovarianceAndCorrelation(d, constant, value); corr != 0 {
+		t.Errorf("corr with a constant = %f, want 0", corr)
+	}
+}
+
+func TestSimulateUnderSupported(t *testing.T) {
+	// masses sum to 0.98, so the distribution is not fully supported
+	masses := map[Outcome]Probability{1: 0.49, 2: 0.245, 3: 0.245}
+	d := newDistribution(set.WithElements(1, 2, 3), masses)
+
+	const n = 200000
+	counts := make(map[Outcome]int)
+
+	for i := 0; i < n; i++ {
+		counts[Simulate(d)]++
+	}
+
+	for o, p := range masses {
+		freq, want := float64(counts[o])/n, float64(p)/0.98
+		if math.Abs(freq-want) > 0.01 {
+			t.Errorf("frequency of %v = %f, want %f", o, freq, want)
+		}
+	}
+}
+
+func TestSimulateNoSupport(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2, 3))
+
+	assertPanics(t, func() {
+		Simulate(d)
+	})
+}
+
+func TestSpearmanCorrelation(t *testing.T) {
+	d := die()
+	exp := func(o Outcome) float64 {
+		return math.Exp(value(o))
+	}
+	negexp := func(o Outcome) float64 {
+		return -exp(o)
+	}
+
+	if rho := SpearmanCorrelation(d, value, exp); !equiv(rho, 1) {
+		t.Errorf("SpearmanCorrelation(X, e^X) = %f, want 1", rho)
+	}
+
+	if _, r := CovarianceAndCorrelation(d, value, exp); r >= 1-epsilon {
+		t.Errorf("Correlation(X, e^X) = %f, want < 1", r)
+	}
+
+	if rho := SpearmanCorrelation(d, value, negexp); !equiv(rho, -1) {
+		t.Errorf("SpearmanCorrelation(X, -e^X) = %f, want -1", rho)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	d := die()
+	calls := make(map[Outcome]int)
+
+	X := Memoize(func(o Outcome) float64 {
+		calls[o]++
+		return value(o)
+	})
+
+	Expectation(d, X)
+	Variance(d, X)
+	Moment(d, X, 3)
+
+	for _, o := range d.Outcomes().Elements() {
+		if calls[o] != 1 {
+			t.Errorf("X(%v) evaluated %d times, want 1", o, calls[o])
+		}
+	}
+
+	if v := Variance(d, X); !equiv(v, Variance(d, value)) {
+		t.Errorf("Variance(d, Memoize(X)) = %f, want %f", v, Variance(d, value))
+	}
+}
+
+func TestExpectationAndVarianceSlices(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2, 5), map[Outcome]Probability{1: 0.2, 2: 0.5, 5: 0.3})
+	values, probs := []float64{1, 2, 5}, []Probability{0.2, 0.5, 0.3}
+
+	if e := ExpectationSlices(values, probs); !equiv(e, Expectation(d, value)) {
+		t.Errorf("ExpectationSlices = %f, want %f", e, Expectation(d, value))
+	}
+
+	if v := VarianceSlices(values, probs); !equiv(v, Variance(d, value)) {
+		t.Errorf("VarianceSlices = %f, want %f", v, Variance(d, value))
+	}
+}
+
+func TestExpectationSlicesInvalid(t *testing.T) {
+	assertPanics(t, func() {
+		ExpectationSlices([]float64{1, 2}, []Probability{1})
+	})
+
+	assertPanics(t, func() {
+		ExpectationSlices([]float64{1, 2}, []Probability{0.5, 0.4})
+	})
+}
+
+func TestExpectationChecked(t *testing.T) {
+	d := die()
+
+	e, err := ExpectationChecked(d, value)
+	if err != nil || !equiv(e, 3.5) {
+		t.Errorf("ExpectationChecked(d, X) = %f, %v, want 3.5, nil", e, err)
+	}
+
+	inverse := func(o Outcome) float64 {
+		return 1 / (value(o) - 4)
+	}
+
+	if _, err := ExpectationChecked(d, inverse); err == nil {
+		t.Errorf("ExpectationChecked(d, 1/(X-4)) succeeded, want error")
+	} else if !strings.Contains(err.Error(), "+Inf at outcome 4") {
+		t.Errorf("ExpectationChecked error = %q, want it to name outcome 4", err)
+	}
+}
+
+func TestAddOutcomeZero(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2, 3))
+	d.AddOutcome(1, 0.5)
+	d.AddOutcome(2, Impossible)
+
+	if s := Support(d); s != 0.5 {
+		t.Errorf("Support(d) = %f after adding a zero mass, want 0.5", s)
+	}
+
+	if p := d.ProbabilityOf(2); p != Impossible {
+		t.Errorf("ProbabilityOf(2) = %f, want Impossible", p)
+	}
+
+	d.AddOutcome(3, 0.5)
+
+	if !FullySupported(d) {
+		t.Errorf("distribution not fully supported")
+	}
+}
+
+func TestAddOutcomeNegative(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+
+	assertPanics(t, func() {
+		d.AddOutcome(2, -0.1)
+	})
+}
+
+func TestSimulateWithDeterministic(t *testing.T) {
+	r1, r2 := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100; i++ {
+		// separate distributions, so nothing is shared between draws
+		if o1, o2 := SimulateWith(die(), r1), SimulateWith(die(), r2); o1 != o2 {
+			t.Fatalf("draw %d: %v != %v, from the same seed", i, o1, o2)
+		}
+	}
+}
+
+func TestProbabilityArithmetic(t *testing.T) {
+	if p := Probability(0.7).Add(0.4); p != Certain {
+		t.Errorf("0.7 + 0.4 = %f, want clamped to 1", p)
+	}
+
+	if p := Probability(-1e-9).Clamp(); p != Impossible {
+		t.Errorf("Clamp(-1e-9) = %g, want 0", p)
+	}
+
+	if p := Probability(0.5).Mul(0.5); p != 0.25 {
+		t.Errorf("0.5 * 0.5 = %f, want 0.25", p)
+	}
+
+	if p := Probability(0.3).Clamp(); p != 0.3 {
+		t.Errorf("Clamp(0.3) = %f, want 0.3", p)
+	}
+}
+
+func TestSupportContains(t *testing.T) {
+	target := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{0, 0, 1, 1, 1, 1})
+	proposal := die()
+	narrow := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 0})
+
+	if !SupportContains(proposal, target) {
+		t.Errorf("uniform proposal does not cover the target")
+	}
+
+	if SupportContains(narrow, target) {
+		t.Errorf("proposal without 6 covers a target supporting 6")
+	}
+
+	if !SupportContains(target, target) {
+		t.Errorf("target does not cover itself")
+	}
+}
+
+func TestNewCategorical(t *testing.T) {
+	d := NewCategorical(Outcomes{"a", "b", "c"}, []float64{2, 6, 2})
+
+	for o, want := range map[Outcome]float64{"a": 0.2, "b": 0.6, "c": 0.2} {
+		if p := d.ProbabilityOf(o); !equiv(float64(p), want) {
+			t.Errorf("ProbabilityOf(%v) = %f, want %f", o, p, want)
+		}
+	}
+
+	if !FullySupported(d) {
+		t.Errorf("distribution not fully supported")
+	}
+}
+
+func TestNewCategoricalInvalid(t *testing.T) {
+	assertPanics(t, func() {
+		NewCategorical(Outcomes{"a", "b"}, []float64{1})
+	})
+
+	assertPanics(t, func() {
+		NewCategorical(Outcomes{"a", "b"}, []float64{1, -1})
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+	d.AddOutcome(1, 0.2)
+	d.AddOutcome(2, 0.2)
+
+	Normalize(d)
+
+	for _, o := range []Outcome{1, 2} {
+		if p := d.ProbabilityOf(o); !equiv(float64(p), 0.5) {
+			t.Errorf("ProbabilityOf(%v) = %f, want 0.5", o, p)
+		}
+	}
+
+	if !FullySupported(d) {
+		t.Errorf("distribution not fully supported after Normalize")
+	}
+}
+
+func TestNormalizeNoSupport(t *testing.T) {
+	assertPanics(t, func() {
+		Normalize(NewDiscreteDistribution(set.WithElements(1, 2)))
+	})
+}
+
+func TestCDFAndQuantile(t *testing.T) {
+	d := die()
+
+	for v, want := range map[float64]float64{0: 0, 1: 1.0 / 6, 3: 0.5, 3.5: 0.5, 6: 1, 10: 1} {
+		if c := CDF(d, value, v); !equiv(float64(c), want) {
+			t.Errorf("CDF(d, X, %f) = %f, want %f", v, c, want)
+		}
+	}
+
+	for q, want := range map[Probability]float64{0.1: 1, 0.5: 3, 0.51: 4, 1: 6} {
+		if v := Quantile(d, value, q); v != want {
+			t.Errorf("Quantile(d, X, %f) = %f, want %f", q, v, want)
+		}
+	}
+}
+
+func TestCompound(t *testing.T) {
+	// a Poisson(2) count, truncated where its remaining mass is negligible
+	pmf := Poisson(2)
+	counts := make(Outcomes, 30)
+	weights := make([]float64, 30)
+
+	for k := range counts {
+		counts[k] = k
+		weights[k] = float64(pmf(k))
+	}
+
+	count := NewCategorical(counts, weights)
+	c := Compound(count, die(), 30)
+
+	if !FullySupported(c) {
+		t.Errorf("compound distribution not fully supported")
+	}
+
+	if mean, want := Expectation(c, value), Expectation(count, value)*3.5; !equiv(mean, want) {
+		t.Errorf("E[Σ T_i] = %f, want E[N]E[T] = %f", mean, want)
+	}
+}
+
+func TestRandomVariableCombinators(t *testing.T) {
+	d := die()
+	square := Mul(value, value)
+
+	if e, want := Expectation(d, Add(value, square)), Expectation(d, value)+Expectation(d, square); !equiv(e, want) {
+		t.Errorf("E[X + X²] = %f, want E[X] + E[X²] = %f", e, want)
+	}
+
+	if e := Expectation(d, Add(value, Scale(2, square))); !equiv(e, 3.5+2*91.0/6) {
+		t.Errorf("E[X + 2X²] = %f, want %f", e, 3.5+2*91.0/6)
+	}
+
+	if e := Expectation(d, square); !equiv(e, 91.0/6) {
+		t.Errorf("E[XX] = %f, want %f", e, 91.0/6)
+	}
+
+	if e := Expectation(d, Apply(math.Sqrt, square)); !equiv(e, 3.5) {
+		t.Errorf("E[√(X²)] = %f, want 3.5", e)
+	}
+}
+
+func TestValueTable(t *testing.T) {
+	u4 := NewUniformDiscrete(set.WithElements(1, 2, 3, 4))
+
+	// as in test/main.go
+	X := func(o Outcome) float64 {
+		return map[int]float64{1: 1, 2: 0, 3: -1, 4: 0}[o.(int)]
+	}
+
+	values, probs := ValueTable(u4, X)
+
+	wantValues := []float64{-1, 0, 1}
+	wantProbs := []Probability{0.25, 0.5, 0.25}
+
+	if len(values) != len(wantValues) || len(probs) != len(wantProbs) {
+		t.Fatalf("ValueTable = %v, %v, want %v, %v", values, probs, wantValues, wantProbs)
+	}
+
+	sum := Impossible
+	for i := range values {
+		if values[i] != wantValues[i] || !equiv(float64(probs[i]), float64(wantProbs[i])) {
+			t.Errorf("entry %d = (%f, %f), want (%f, %f)", i, values[i], probs[i], wantValues[i], wantProbs[i])
+		}
+
+		sum += probs[i]
+	}
+
+	if !equiv(float64(sum), 1) {
+		t.Errorf("probabilities sum to %f, want 1", sum)
+	}
+}
+
+func TestCentralMoments(t *testing.T) {
+	d := die()
+
+	if m := CentralMoment(d, value, 1); !equiv(m, 0) {
+		t.Errorf("first central moment = %f, want 0", m)
+	}
+
+	if m := CentralMoment(d, value, 2); !equiv(m, Variance(d, value)) {
+		t.Errorf("second central moment = %f, want Variance = %f", m, Variance(d, value))
+	}
+
+	if s := Skewness(d, value); !equiv(s, 0) {
+		t.Errorf("Skewness(die) = %f, want 0", s)
+	}
+
+	// 3 - 6(n² + 1) / 5(n² - 1), for a discrete uniform over n values
+	if k, want := Kurtosis(d, value), 3-6*37.0/(5*35); !equiv(k, want) {
+		t.Errorf("Kurtosis(die) = %f, want %f", k, want)
+	}
+
+	skewed := NewCategorical(Outcomes{1, 2, 10}, []float64{0.6, 0.3, 0.1})
+	if s := Skewness(skewed, value); s <= 0 {
+		t.Errorf("Skewness of a right tailed distribution = %f, want positive", s)
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	d := die()
+	square := Mul(value, value)
+
+	for _, X := range []RandomVariable{value, square, Scale(0.001, value), Scale(1e6, value)} {
+		if r := Correlation(d, X, X); !equiv(r, 1) {
+			t.Errorf("Corr(X, X) = %f, want 1", r)
+		}
+
+		if r := Correlation(d, X, Scale(-1, X)); !equiv(r, -1) {
+			t.Errorf("Corr(X, -X) = %f, want -1", r)
+		}
+	}
+
+	// the values of two independent dice
+	two := dice()
+	X := func(o Outcome) float64 { return value(first(o)) }
+	Y := func(o Outcome) float64 { return value(second(o)) }
+
+	if r := Correlation(two, X, Y); !equiv(r, 0) {
+		t.Errorf("Corr(X, Y) = %f, want 0 for independent X and Y", r)
+	}
+
+	if r := Correlation(d, value, square); r < -1 || r > 1 {
+		t.Errorf("Corr(X, X²) = %f, want on [-1, 1]", r)
+	}
+}
+
+func TestIndependentVariables(t *testing.T) {
+	two := dice()
+
+	// the covariance of these computes to about -3e-17, not exactly 0
+	X := func(o Outcome) float64 { return 0.1 * value(first(o)) }
+	Y := func(o Outcome) float64 { return 0.1 * value(second(o)) }
+
+	if !IndependentVariables(two, X, Y) {
+		t.Errorf("independent variables with Cov(X, Y) = %g reported dependent", Covariance(two, X, Y))
+	}
+
+	if IndependentVariables(two, X, Add(X, Y)) {
+		t.Errorf("X and X + Y reported independent")
+	}
+}
+
+func TestSetEpsilon(t *testing.T) {
+	defer SetEpsilon(Epsilon())
+
+	// short of full support by 1e-7
+	d := newDistribution(set.WithElements("a", "b"), map[Outcome]Probability{
+		"a": 0.5,
+		"b": 0.5 - 1e-7,
+	})
+
+	if !FullySupported(d) {
+		t.Errorf("FullySupported = false with epsilon %g, want true", Epsilon())
+	}
+
+	SetEpsilon(1e-9)
+
+	if FullySupported(d) {
+		t.Errorf("FullySupported = true with epsilon %g, want false", Epsilon())
+	}
+}
+
+func TestMode(t *testing.T) {
+	if m := Mode(die()); len(m) != 6 {
+		t.Errorf("Mode(die) = %v, want all six faces", m)
+	}
+
+	skewed := NewCategorical(Outcomes{1, 2, 3}, []float64{0.2, 0.5, 0.3})
+	if m := Mode(skewed); len(m) != 1 || m[0] != 2 {
+		t.Errorf("Mode(skewed) = %v, want [2]", m)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	four := NewUniformDiscrete(set.WithElements(1, 2, 3, 4))
+	if m := Median(four, value); !equiv(m, 2.5) {
+		t.Errorf("Median(uniform {1, 2, 3, 4}) = %f, want 2.5", m)
+	}
+
+	three := NewUniformDiscrete(set.WithElements(1, 2, 3))
+	if m := Median(three, value); !equiv(m, 2) {
+		t.Errorf("Median(uniform {1, 2, 3}) = %f, want 2", m)
+	}
+}
+
+func TestConditionalExpectation(t *testing.T) {
+	d := die()
+	even := set.WithElements(2, 4, 6)
+
+	if e := ConditionalExpectation(d, value, even); !equiv(e, 4) {
+		t.Errorf("E[X | even] = %f, want 4", e)
+	}
+}
+
+func TestConditionalExpectationImpossible(t *testing.T) {
+	d := die()
+
+	assertPanics(t, func() {
+		ConditionalExpectation(d, value, set.WithElements(7))
+	})
+}
+
+func TestEmpirical(t *testing.T) {
+	d := NewCategorical(Outcomes{1, 2, 3, 4}, []float64{0.1, 0.2, 0.3, 0.4})
+	e := Empirical(Sample(d, 1000))
+
+	if !FullySupported(e) {
+		t.Errorf("Empirical(samples) is not fully supported")
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		if got, want := e.ProbabilityOf(o), d.ProbabilityOf(o); math.Abs(float64(got-want)) > 0.05 {
+			t.Errorf("Empirical(samples).ProbabilityOf(%v) = %f, want %f", o, got, want)
+		}
+	}
+}
+
+func TestMixture(t *testing.T) {
+	p := die()
+	q := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 5})
+
+	if m := Mixture([]DiscreteDistribution{p, q}, []Probability{0.3, 0.7}); !sameMasses(m, Compose(p, q, 0.3)) {
+		t.Errorf("Mixture(p, q; 0.3, 0.7) differs from Compose(p, q, 0.3)")
+	}
+
+	third := Probability(1.0 / 3)
+	if m := Mixture([]DiscreteDistribution{p, p, p}, []Probability{third, third, third}); !sameMasses(m, p) {
+		t.Errorf("Mixture(p, p, p) differs from p")
+	}
+}
+
+func TestSimulateLastOutcome(t *testing.T) {
+	// ten masses of 0.1, whose cumulative sum drifts from 1
+	outcomes := make(Outcomes, 10)
+	weights := make([]float64, 10)
+	for i := range outcomes {
+		outcomes[i], weights[i] = i, 0.1
+	}
+
+	d := NewCategorical(outcomes, weights)
+	r := rand.New(rand.NewSource(1))
+
+	const n = 1000000
+	last := 0
+	for i := 0; i < n; i++ {
+		if SimulateWith(d, r) == 9 {
+			last++
+		}
+	}
+
+	// five standard deviations of the frequency, sqrt(0.1*0.9/n)
+	if freq := float64(last) / n; math.Abs(freq-0.1) > 5*math.Sqrt(0.1*0.9/n) {
+		t.Errorf("frequency of the last outcome = %f, want 0.1", freq)
+	}
+}
+
+func TestPowerSet(t *testing.T) {
+	omega := set.WithElements(1, 2, 3)
+	events := PowerSet(omega)
+
+	if len(events) != 8 {
+		t.Fatalf("len(PowerSet(omega)) = %d, want 8", len(events))
+	}
+
+	empty, full := false, false
+	for _, A := range events {
+		empty = empty || set.Equivalent(A, set.New())
+		full = full || set.Equivalent(A, omega)
+	}
+
+	if !empty || !full {
+		t.Errorf("PowerSet(omega) includes the empty set: %t, and omega: %t, want both", empty, full)
+	}
+}
+
+func TestProbabilityOfComplement(t *testing.T) {
+	d := die()
+	even := set.WithElements(2, 4, 6)
+
+	if p := ProbabilityOf(d, even) + ProbabilityOfComplement(d, even); !equiv(float64(p), 1) {
+		t.Errorf("P(even) + P(odd) = %f, want 1", p)
+	}
+
+	if odd := Complement(d, even); !set.Equivalent(odd, set.WithElements(1, 3, 5)) {
+		t.Errorf("Complement(d, even) = %v, want {1, 3, 5}", odd.Elements())
+	}
+}
+
+func TestConditionalProbability(t *testing.T) {
+	d := die()
+	two, even := set.WithElements(2), set.WithElements(2, 4, 6)
+
+	if p := ConditionalProbability(d, two, even); !equiv(float64(p), 1.0/3) {
+		t.Errorf("P(2 | even) = %f, want %f", p, 1.0/3)
+	}
+
+	if p := ConditionalProbability(d, even, even); !equiv(float64(p), 1) {
+		t.Errorf("P(even | even) = %f, want 1", p)
+	}
+}
+
+func TestConditionalProbabilityImpossible(t *testing.T) {
+	d := die()
+	two := set.WithElements(2)
+
+	assertPanics(t, func() {
+		ConditionalProbability(d, two, set.WithElements(7))
+	})
+}
+
+func TestExpectationPrecise(t *testing.T) {
+	d := die()
+
+	if e, _ := ExpectationPrecise(d, value).Float64(); !equiv(e, Expectation(d, value)) {
+		t.Errorf("ExpectationPrecise = %f, want Expectation = %f", e, Expectation(d, value))
+	}
+
+	// the large values cancel, leaving E[X] = P(b)
+	p := NewCategorical(Outcomes{"a", "b", "c"}, []float64{1, 1, 1})
+	X := func(o Outcome) float64 {
+		return map[Outcome]float64{"a": 1e16, "b": 1, "c": -1e16}[o]
+	}
+	want := float64(p.ProbabilityOf("b"))
+
+	// summed in this order, the float64 accumulator loses P(b)
+	naive := ExpectationSlices(
+		[]float64{X("a"), X("b"), X("c")},
+		[]Probability{p.ProbabilityOf("a"), p.ProbabilityOf("b"), p.ProbabilityOf("c")},
+	)
+	if naive == want {
+		t.Fatalf("naive sum = %g, want it to drift from %g", naive, want)
+	}
+
+	if e, _ := ExpectationPrecise(p, X).Float64(); e != want {
+		t.Errorf("ExpectationPrecise = %g, want %g (naive sum %g)", e, want, naive)
+	}
+}
+
+func TestClone(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+	d.AddOutcome(1, 0.5)
+
+	c := Clone(d)
+	c.AddOutcome(2, 0.5)
+
+	if p := d.ProbabilityOf(2); p != Impossible {
+		t.Errorf("P(2) = %f after adding to the clone, want 0", p)
+	}
+
+	if d.Outcomes().Contains(2) {
+		t.Errorf("outcomes of d contain 2 after adding to the clone")
+	}
+
+	if p := c.ProbabilityOf(2); !equiv(float64(p), 0.5) {
+		t.Errorf("clone P(2) = %f, want 0.5", p)
+	}
+}
+
+func TestDistributionsEqual(t *testing.T) {
+	manual := NewDiscreteDistribution(set.WithElements(1, 2, 3, 4, 5, 6))
+	for face := 1; face <= 6; face++ {
+		manual.AddOutcome(face, Probability(1.0/6))
+	}
+
+	if !DistributionsEqual(die(), manual) {
+		t.Errorf("uniform die differs from one built with masses of 1/6")
+	}
+
+	skewed := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 1.01})
+	if DistributionsEqual(die(), skewed) {
+		t.Errorf("uniform die equals a die skewed by more than epsilon")
+	}
+
+	coin := NewUniformDiscrete(set.WithElements(1, 2))
+	if DistributionsEqual(die(), coin) {
+		t.Errorf("distributions over different domains are equal")
+	}
+}
+
+func TestNewDegenerate(t *testing.T) {
+	d := NewDegenerate(3, set.WithElements(1, 2, 3, 4, 5, 6))
+
+	if !Degenerate(d) {
+		t.Errorf("Degenerate(NewDegenerate(3, ...)) = false, want true")
+	}
+
+	square := Mul(value, value)
+	for _, X := range []RandomVariable{value, square} {
+		if e := Expectation(d, X); !equiv(e, X(3)) {
+			t.Errorf("Expectation = %f, want X(3) = %f", e, X(3))
+		}
+	}
+}
+
+func TestNewDegenerateOutsideDomain(t *testing.T) {
+	assertPanics(t, func() {
+		NewDegenerate(7, set.WithElements(1, 2, 3))
+	})
+}
+
+func TestIndicator(t *testing.T) {
+	d := die()
+
+	for _, A := range []Event{
+		set.New(),
+		set.WithElements(2, 4, 6),
+		set.WithElements(1, 2),
+		set.WithElements(6),
+		set.WithElements(1, 2, 3, 4, 5, 6),
+	} {
+		if e, p := Expectation(d, Indicator(A)), ProbabilityOf(d, A); !equiv(e, float64(p)) {
+			t.Errorf("E[1_A] = %f, want P(A) = %f, for A = %v", e, p, A.Elements())
+		}
+	}
+}
+
+func TestPushForward(t *testing.T) {
+	sum := func(o Outcome) float64 {
+		return value(first(o)) + value(second(o))
+	}
+
+	d := PushForward(Product(die(), die()), sum)
+
+	if c := Cardinality(d); c != 11 {
+		t.Errorf("Cardinality = %d, want 11", c)
+	}
+
+	// P(s) = (6 - |s - 7|)/36, for s ∈ [2, 12]
+	for s := 2; s <= 12; s++ {
+		want := (6 - math.Abs(float64(s-7))) / 36
+		if p := d.ProbabilityOf(float64(s)); !equiv(float64(p), want) {
+			t.Errorf("P(%d) = %f, want %f", s, p, want)
+		}
+	}
+}
+
+func TestNewFromCounts(t *testing.T) {
+	d := NewFromCounts(map[Outcome]int{"a": 3, "b": 1}, set.WithElements("a", "b", "c"))
+
+	if p := d.ProbabilityOf("a"); !equiv(float64(p), 0.75) {
+		t.Errorf("P(a) = %f, want 0.75", p)
+	}
+
+	if p := d.ProbabilityOf("b"); !equiv(float64(p), 0.25) {
+		t.Errorf("P(b) = %f, want 0.25", p)
+	}
+
+	if !d.Domain().Contains("c") {
+		t.Errorf("domain does not contain the unobserved outcome c")
+	}
+
+	if p := d.ProbabilityOf("c"); p != Impossible {
+		t.Errorf("P(c) = %f, want 0", p)
+	}
+}
+
+func TestProbabilityOfSafe(t *testing.T) {
+	d := die()
+
+	if p, ok := ProbabilityOfSafe(d, 7); p != Impossible || ok {
+		t.Errorf("ProbabilityOfSafe(d, 7) = (%f, %t), want (0, false)", p, ok)
+	}
+
+	if p, ok := ProbabilityOfSafe(d, 3); !equiv(float64(p), 1.0/6) || !ok {
+		t.Errorf("ProbabilityOfSafe(d, 3) = (%f, %t), want (%f, true)", p, ok, 1.0/6)
+	}
+
+	partial := NewDiscreteDistribution(set.WithElements(1, 2))
+	partial.AddOutcome(1, 0.5)
+
+	if p, ok := ProbabilityOfSafe(partial, 2); p != Impossible || !ok {
+		t.Errorf("ProbabilityOfSafe(partial, 2) = (%f, %t), want (0, true)", p, ok)
+	}
+
+	// the method on a distribution agrees with the package function
+	c := NewCategorical(Outcomes{"H", "T"}, []float64{1, 3}).(*distribution)
+
+	if p, ok := c.ProbabilityOfSafe("E"); p != Impossible || ok {
+		t.Errorf("c.ProbabilityOfSafe(E) = (%f, %t), want (0, false)", p, ok)
+	}
+
+	if p, ok := c.ProbabilityOfSafe("T"); !equiv(float64(p), 0.75) || !ok {
+		t.Errorf("c.ProbabilityOfSafe(T) = (%f, %t), want (0.75, true)", p, ok)
+	}
+}
+
+// moments constructs the random variables X, X^2, ..., X^n
+func moments(n int) []RandomVariable {
+	Xs := make([]RandomVariable, n)
+	for i := range Xs {
+		k := float64(i + 1)
+		Xs[i] = func(o Outcome) float64 { return math.Pow(value(o), k) }
+	}
+
+	return Xs
+}
+
+func TestExpectationVector(t *testing.T) {
+	d := die()
+	Xs := moments(4)
+
+	exps := ExpectationVector(d, Xs)
+	if len(exps) != len(Xs) {
+		t.Fatalf("len(ExpectationVector) = %d, want %d", len(exps), len(Xs))
+	}
+
+	for i, X := range Xs {
+		if want := Expectation(d, X); !equiv(exps[i], want) {
+			t.Errorf("E[X^%d] = %f, want Expectation = %f", i+1, exps[i], want)
+		}
+	}
+}
+
+func BenchmarkExpectationVector(b *testing.B) {
+	d, Xs := large(1000), moments(8)
+
+	for i := 0; i < b.N; i++ {
+		ExpectationVector(d, Xs)
+	}
+}
+
+func BenchmarkExpectationPerVariable(b *testing.B) {
+	d, Xs := large(1000), moments(8)
+
+	for i := 0; i < b.N; i++ {
+		for _, X := range Xs {
+			Expectation(d, X)
+		}
+	}
+}
+
+func TestCovarianceMatrix(t *testing.T) {
+	d := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 2, 3, 3, 2, 1})
+	Xs := append(moments(3), Indicator(set.WithElements(2, 4, 6)))
+
+	cov := CovarianceMatrix(d, Xs)
+
+	for i, X := range Xs {
+		if v := Variance(d, X); !equiv(cov[i][i], v) {
+			t.Errorf("cov[%d][%d] = %f, want Variance = %f", i, i, cov[i][i], v)
+		}
+
+		for j := range Xs {
+			if !equiv(cov[i][j], cov[j][i]) {
+				t.Errorf("cov[%d][%d] = %f, but cov[%d][%d] = %f", i, j, cov[i][j], j, i, cov[j][i])
+			}
+		}
+	}
+
+	if c := Covariance(d, Xs[0], Xs[3]); !equiv(cov[0][3], c) {
+		t.Errorf("cov[0][3] = %f, want Covariance = %f", cov[0][3], c)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	high := Truncate(die(), func(o Outcome) bool { return o.(int) > 3 })
+
+	for face := 1; face <= 6; face++ {
+		want := 0.0
+		if face > 3 {
+			want = 1.0 / 3
+		}
+
+		if p := high.ProbabilityOf(face); !equiv(float64(p), want) {
+			t.Errorf("P(%d) = %f, want %f", face, p, want)
+		}
+	}
+}
+
+func TestTruncateImpossible(t *testing.T) {
+	assertPanics(t, func() {
+		Truncate(die(), func(o Outcome) bool { return o.(int) > 6 })
+	})
+}
+
+func TestExpectationOfProduct(t *testing.T) {
+	d := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 2, 3, 3, 2, 1})
+	Y := Indicator(set.WithElements(2, 4, 6))
+
+	if e, want := ExpectationOfProduct(d, value, Y), Expectation(d, Mul(value, Y)); !equiv(e, want) {
+		t.Errorf("E[XY] = %f, want %f", e, want)
+	}
+
+	// (1 + 8 + 27 + 64 + 125 + 216)/6
+	if e := ExpectationOfProduct(die(), value, value, value); !equiv(e, 441.0/6) {
+		t.Errorf("E[X^3] over a die = %f, want %f", e, 441.0/6)
+	}
+}
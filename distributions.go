@@ -1,8 +1,11 @@
 package prob
 
 import (
+	"errors"
 	"math"
 	"math/big"
+
+	"github.com/nlandolfi/set"
 )
 
 // Bernoulli represents a Bernoulli trial
@@ -17,48 +20,158 @@ func Bernoulli(p Probability) func(k int) Probability {
 	}
 }
 
+// NewBernoulli constructs the discrete distribution of a Bernoulli
+// trial, over the domain {0, 1}
+func NewBernoulli(p Probability) DiscreteDistribution {
+	assert(p.Valid(), "invalid probability")
+
+	return newDistribution(set.WithElements(0, 1), map[Outcome]Probability{
+		0: 1 - p,
+		1: p,
+	})
+}
+
 // A Binomial distribution. The number of successes in n independent trials
 // with a probability, p, of success in each trial.
 // (n choose k)(p)^(k)(1-p)^(n-k)
+//
+// The probability is computed in log-space, so it remains accurate
+// when (n choose k) exceeds the range of an int64.
 func Binomial(n int64, p Probability) func(int64) Probability {
 	return func(k int64) Probability {
-		return Probability(float64(nint(0).Binomial(n, k).Int64()) * math.Pow(float64(p), float64(k)) * math.Pow(1-float64(p), float64(n-k)))
+		if k < 0 || k > n {
+			return Impossible
+		}
+
+		return Probability(math.Exp(logChoose(n, k) + xlogy(float64(k), float64(p)) + xlogy(float64(n-k), 1-float64(p))))
 	}
 }
 
+// BinomialCDF is the cumulative distribution function of the Binomial
+// distribution: the probability of at most k successes in n trials.
+// P(X ≤ k) = Σ_{i ≤ k} (n choose i)(p)^(i)(1-p)^(n-i)
+func BinomialCDF(n int64, p Probability) func(int64) Probability {
+	pmf := Binomial(n, p)
+
+	return func(k int64) Probability {
+		if k >= n {
+			return Certain
+		}
+
+		c := Impossible
+		for i := int64(0); i <= k; i++ {
+			c += pmf(i)
+		}
+
+		return c.Clamp()
+	}
+}
+
+// BinomialQuantile is the quantile function (inverse CDF) of the
+// Binomial distribution: the smallest k for which P(X ≤ k) ≥ q, with
+// respect to epsilon.
+func BinomialQuantile(n int64, p Probability) func(Probability) int64 {
+	assert(n >= 0, "negative number of trials")
+	assert(p.Valid(), "invalid probability")
+
+	pmf := Binomial(n, p)
+
+	return func(q Probability) int64 {
+		assert(q.Valid(), "invalid probability")
+
+		c := Impossible
+		for k := int64(0); k < n; k++ {
+			c += pmf(k)
+
+			if float64(c) > float64(q)-epsilon {
+				return k
+			}
+		}
+
+		return n
+	}
+}
+
+// A BetaBinomial distribution. The number of successes in n independent
+// trials, where the probability of success is itself drawn from a
+// Beta(alpha, beta) distribution. It models overdispersed counts.
+// (n choose k)B(k+α, n-k+β)/B(α, β)
+//
+// The beta function is computed in log-space, so the probability remains
+// accurate for large n.
+func BetaBinomial(n int64, alpha, beta float64) func(int64) Probability {
+	assert(alpha > 0 && beta > 0, "beta parameters must be positive")
+
+	return func(k int64) Probability {
+		if k < 0 || k > n {
+			return Impossible
+		}
+
+		return Probability(math.Exp(logChoose(n, k) + logBeta(float64(k)+alpha, float64(n-k)+beta) - logBeta(alpha, beta)))
+	}
+}
+
+// NewBinomial constructs the discrete distribution of the number of
+// successes in n independent trials, over the domain {0, 1, ..., n}.
+// See Binomial.
+func NewBinomial(n int64, p Probability) DiscreteDistribution {
+	assert(n >= 0, "negative number of trials")
+	assert(p.Valid(), "invalid probability")
+
+	pmf := Binomial(n, p)
+	domain := set.New()
+	masses := make(map[Outcome]Probability)
+
+	for k := int64(0); k <= n; k++ {
+		domain.Add(int(k))
+		masses[int(k)] = pmf(k)
+	}
+
+	return newDistribution(domain, masses)
+}
+
 // A Multinomial distribution. The number of elements in each category
 // where the probability of being in category i is probabilities[i].
+//
+// The probability is computed in log-space, so it remains accurate
+// when the multinomial coefficient exceeds the range of an int64.
 func Multinomial(probabilities ...Probability) func(...int) Probability {
 	return func(partition ...int) Probability {
 		assert(len(probabilities) == len(partition), "invalid partition")
 
 		sum := 0
 		for i := range partition {
+			assert(partition[i] >= 0, "partition can't be negative")
 			sum += partition[i]
 		}
 
 		assert(sum != 0, "partition sum can't be zero")
 
-		num := Factorial(nint(int64(sum)))
-		den := nint(1)
+		logp := LogFactorial(sum)
 
 		for i := range partition {
-			den.Mul(den, Factorial(nint(int64(partition[i]))))
+			logp += xlogy(float64(partition[i]), float64(probabilities[i])) - LogFactorial(partition[i])
 		}
 
-		scale := 1.0
-		for i := range probabilities {
-			scale *= math.Pow(float64(probabilities[i]), float64(partition[i]))
-		}
-
-		return Probability(float64(num.Div(num, den).Int64()) * scale)
+		return Probability(math.Exp(logp))
 	}
 }
 
 // A Uniform distribution on the discrete range [1, 2, ..., n]
 func Uniform(n int) func(int) Probability {
+	return DiscreteUniform(1, n)
+}
+
+// A DiscreteUniform distribution on the discrete range [a, a+1, ..., b]
+func DiscreteUniform(a, b int) func(int) Probability {
+	assert(a <= b, "empty range")
+
 	return func(k int) Probability {
-		return Probability(1.0 / float64(n))
+		if k < a || k > b {
+			return Impossible
+		}
+
+		return Probability(1.0 / float64(b-a+1))
 	}
 }
 
@@ -67,24 +180,316 @@ func Uniform(n int) func(int) Probability {
 // Recall that the geometric distribution models the probability that
 // it takes k trials until we observe a success, where probability of a
 // success in p
+//
+// Note: this is the "number of trials" convention, with support starting
+// at k = 1. See GeometricFailures for the "number of failures"
+// convention, with support starting at k = 0.
 func Geometric(p Probability) func(int) Probability {
 	return func(k int) Probability {
+		if k < 1 {
+			return Impossible
+		}
+
 		return Probability(math.Pow(float64(Certain-p), float64(k-1)) * float64(p))
 	}
 }
 
+// GeometricCDF is the cumulative distribution function of the Geometric
+// distribution: the probability that a success is observed within the
+// first k trials.
+// P(X ≤ k) = 1-(1-p)^(k), for k ≥ 1
+func GeometricCDF(p Probability) func(int) Probability {
+	return func(k int) Probability {
+		if k < 1 {
+			return Impossible
+		}
+
+		return Probability(1 - math.Pow(float64(Certain-p), float64(k))).Clamp()
+	}
+}
+
+// GeometricQuantile is the quantile function (inverse CDF) of the
+// Geometric distribution: the smallest k for which P(X ≤ k) ≥ q, with
+// respect to epsilon. The probability of success must lie on the
+// interval (0, 1], and, as the support is unbounded, q must be less
+// than 1.
+//
+// The quantile is computed in closed form, rather than by accumulating
+// the PMF: P(X ≤ k) > q - ε iff k > log(1-q+ε)/log(1-p)
+func GeometricQuantile(p Probability) func(Probability) int {
+	assert(p > Impossible && p <= Certain, "probability of success must be on (0, 1]")
+
+	return func(q Probability) int {
+		assert(q.Valid(), "invalid probability")
+		assert(q < Certain, "no finite quantile of 1")
+
+		t := float64(q) - epsilon
+		if p == Certain || t <= 0 {
+			return 1
+		}
+
+		return int(math.Floor(math.Log1p(-t)/math.Log1p(-float64(p)))) + 1
+	}
+}
+
+// A GeometricFailures distribution with parameter p.
+//
+// Recall that this geometric distribution models the probability that
+// we observe k failures before a success, where probability of a success
+// is p. So GeometricFailures(p)(k) = Geometric(p)(k+1).
+// (1-p)^(k) p, for k ≥ 0
+//
+// Note: this is the "number of failures" convention, with support
+// starting at k = 0. See Geometric for the "number of trials" convention.
+func GeometricFailures(p Probability) func(int) Probability {
+	return func(k int) Probability {
+		if k < 0 {
+			return Impossible
+		}
+
+		return Probability(math.Pow(float64(Certain-p), float64(k)) * float64(p))
+	}
+}
+
+// A NegativeBinomial distribution with parameters r and p.
+//
+// Recall that the negative binomial distribution models the probability
+// that it takes k trials until we observe r successes, where probability
+// of a success is p. So, for r = 1 it is the Geometric distribution.
+// (k-1 choose r-1)(p)^(r)(1-p)^(k-r), for k ≥ r
+//
+// The probability is computed in log-space, so it remains accurate
+// when (k-1 choose r-1) exceeds the range of an int64.
+//
+// The number of successes, r, must be at least 1.
+func NegativeBinomial(r int64, p Probability) func(int64) Probability {
+	assert(r >= 1, "number of successes must be positive")
+	assert(p.Valid(), "invalid probability")
+
+	return func(k int64) Probability {
+		if k < r {
+			return Impossible
+		}
+
+		return Probability(math.Exp(logChoose(k-1, r-1) + xlogy(float64(r), float64(p)) + xlogy(float64(k-r), 1-float64(p))))
+	}
+}
+
+// A Hypergeometric distribution with parameters N, K and n.
+//
+// Recall that the hypergeometric distribution models the probability
+// of k successes when drawing n items, without replacement, from a
+// population of N items of which K are successes.
+// (K choose k)(N-K choose n-k)/(N choose n)
+//
+// The combinations are computed in log-space, so they remain accurate
+// for large populations.
+func Hypergeometric(N, K, n int64) func(int64) Probability {
+	assert(0 <= K && K <= N, "invalid number of successes")
+	assert(0 <= n && n <= N, "invalid number of draws")
+
+	return func(k int64) Probability {
+		if k < 0 || k < n-(N-K) || k > n || k > K {
+			return Impossible
+		}
+
+		return Probability(math.Exp(logChoose(K, k) + logChoose(N-K, n-k) - logChoose(N, n)))
+	}
+}
+
 // A Poisson distribution with paramter mu.
 //
 // Recall that the poisson distribution models the probability that we
 // observe k successes in infinite trials; In other words, it models
 // the expected number of occurrences in an interval of time t of a randomly
 // occuring process with rate mu per t.
+//
+// The probability is computed in log-space, so it remains accurate
+// when k! exceeds the range of an int64.
 func Poisson(mu float64) func(int) Probability {
 	return func(k int) Probability {
-		return Probability(math.Pow(math.E, -mu) * math.Pow(mu, float64(k)) / float64(Factorial(big.NewInt(int64(k))).Int64()))
+		if k < 0 {
+			return Impossible
+		}
+
+		return Probability(math.Exp(-mu + xlogy(float64(k), mu) - LogFactorial(k)))
+	}
+}
+
+// PoissonCDF is the cumulative distribution function of the Poisson
+// distribution: the probability of at most k occurrences.
+// P(X ≤ k) = Σ_{i ≤ k} e^(-mu) mu^(i) / i!
+func PoissonCDF(mu float64) func(int) Probability {
+	pmf := Poisson(mu)
+
+	return func(k int) Probability {
+		c := Impossible
+		for i := 0; i <= k; i++ {
+			c += pmf(i)
+		}
+
+		return c.Clamp()
+	}
+}
+
+// PoissonQuantile is the quantile function (inverse CDF) of the
+// Poisson distribution: the smallest k for which P(X ≤ k) ≥ q, with
+// respect to epsilon. The rate, mu, must be non-negative, and, as the
+// support is unbounded, q must be less than 1.
+//
+// If q lies beyond the cumulative probability a float64 can resolve,
+// e.g., with a very small epsilon, the first k past the mean at which
+// the cumulative probability stops increasing is returned.
+func PoissonQuantile(mu float64) func(Probability) int {
+	assert(mu >= 0, "negative rate")
+
+	pmf := Poisson(mu)
+
+	return func(q Probability) int {
+		assert(q.Valid(), "invalid probability")
+		assert(q < Certain, "no finite quantile of 1")
+
+		c := Impossible
+		for k := 0; ; k++ {
+			next := c + pmf(k)
+
+			if float64(next) > float64(q)-epsilon || (float64(k) > mu && next == c) {
+				return k
+			}
+
+			c = next
+		}
+	}
+}
+
+// BetaFromMeanVar computes the parameters, alpha and beta, of the
+// Beta distribution with the given mean and variance, by matching
+// moments. This allows a Beta prior to be specified intuitively.
+//
+// Recall: for Beta(α, β), the mean is μ = α/(α+β) and the variance
+// is σ² = μ(1-μ)/(α+β+1). So α+β = μ(1-μ)/σ² - 1.
+//
+// The mean must lie on the interval (0, 1), and the variance on the
+// interval (0, μ(1-μ)).
+func BetaFromMeanVar(mean, variance float64) (alpha, beta float64, err error) {
+	if mean <= 0 || mean >= 1 {
+		return 0, 0, errors.New("prob: mean must be on the interval (0, 1)")
+	}
+
+	if variance <= 0 || variance >= mean*(1-mean) {
+		return 0, 0, errors.New("prob: variance infeasible for mean")
+	}
+
+	total := mean*(1-mean)/variance - 1
+
+	return mean * total, (1 - mean) * total, nil
+}
+
+// PMFExpectation computes the expected value of an integer-valued
+// distribution, given by its probability mass function, pmf, over the
+// outcomes in support. This avoids constructing a DiscreteDistribution.
+//
+// Recall: E[X] = Σ_k k P(k)
+//
+//	binomial := Binomial(n, p)
+//	pmf := func(k int) Probability { return binomial(int64(k)) }
+//	PMFExpectation(pmf, []int{0, 1, ..., n}) => n*p
+func PMFExpectation(pmf func(int) Probability, support []int) float64 {
+	e := 0.0
+
+	for _, k := range support {
+		e += float64(k) * float64(pmf(k))
+	}
+
+	return e
+}
+
+// PMFVariance computes the variance of an integer-valued distribution,
+// given by its probability mass function, pmf, over the outcomes in
+// support. See PMFExpectation.
+//
+// Recall: Var(X) = E[(X - E[X])^2]
+func PMFVariance(pmf func(int) Probability, support []int) float64 {
+	mean := PMFExpectation(pmf, support)
+	v := 0.0
+
+	for _, k := range support {
+		v += math.Pow(float64(k)-mean, 2) * float64(pmf(k))
+	}
+
+	return v
+}
+
+// MemoizePMF constructs a probability mass function equivalent to pmf,
+// which evaluates pmf at most once per distinct k. This is useful when
+// pmf is expensive and evaluated repeatedly, e.g., Poisson in a Monte
+// Carlo loop. See Memoize, the analog for random variables.
+//
+// Unlike Memoize, the memoized pmf is not safe for concurrent use.
+func MemoizePMF(pmf func(int) Probability) func(int) Probability {
+	cache := make(map[int]Probability)
+
+	return func(k int) Probability {
+		p, ok := cache[k]
+		if !ok {
+			p = pmf(k)
+			cache[k] = p
+		}
+
+		return p
 	}
 }
 
+// SumsToOne determines whether the probability mass function, pmf,
+// sums to one over the outcomes in support, with respect to epsilon.
+// This is a sanity check for the parameters of a distribution, e.g.,
+// Binomial(n, p) with p > 1 does not sum to one.
+func SumsToOne(pmf func(int) Probability, support []int) bool {
+	sum := 0.0
+
+	for _, k := range support {
+		sum += float64(pmf(k))
+	}
+
+	return equiv(sum, float64(Certain))
+}
+
+// LogGamma computes the natural logarithm of the absolute value of
+// the gamma function, log|Γ(x)|.
+//
+// Recall: Γ(n+1) = n!, for non-negative integers n
+func LogGamma(x float64) float64 {
+	lg, _ := math.Lgamma(x)
+	return lg
+}
+
+// LogFactorial computes log(n!), which remains finite where n!
+// itself exceeds the range of an int64 (n > 20) or float64 (n > 170).
+func LogFactorial(n int) float64 {
+	assert(n >= 0, "factorial of negative number")
+	return LogGamma(float64(n + 1))
+}
+
+// logChoose computes log(n choose k), without overflow
+func logChoose(n, k int64) float64 {
+	return LogGamma(float64(n+1)) - LogGamma(float64(k+1)) - LogGamma(float64(n-k+1))
+}
+
+// logBeta computes log B(a, b), where B is the beta function.
+// Recall: B(a, b) = Γ(a)Γ(b)/Γ(a+b)
+func logBeta(a, b float64) float64 {
+	return LogGamma(a) + LogGamma(b) - LogGamma(a+b)
+}
+
+// xlogy computes x*log(y), taking 0*log(0) to be 0
+func xlogy(x, y float64) float64 {
+	if x == 0 {
+		return 0
+	}
+
+	return x * math.Log(y)
+}
+
 // nint is a helper for big.NewInt
 func nint(i int64) *big.Int {
 	return big.NewInt(i)
@@ -92,13 +497,13 @@ func nint(i int64) *big.Int {
 
 // Factorial computes n!
 func Factorial(n *big.Int) *big.Int {
-	if n.Cmp(nint(0)) == 0 {
-		return big.NewInt(1)
-	}
+	z := nint(1)
 
-	i, z := nint(0), nint(0)
+	for i := nint(2); i.Cmp(n) <= 0; i.Add(i, nint(1)) {
+		z.Mul(z, i)
+	}
 
-	return z.Mul(n, Factorial(i.Sub(n, nint(1))))
+	return z
 }
 
 // Combintation comuptes (n choose k)
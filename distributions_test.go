@@ -0,0 +1,472 @@
+package prob
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestBetaFromMeanVar(t *testing.T) {
+	for _, c := range []struct{ mean, variance float64 }{
+		{0.5, 0.05},
+		{0.2, 0.01},
+		{0.9, 0.0009},
+	} {
+		alpha, beta, err := BetaFromMeanVar(c.mean, c.variance)
+		if err != nil {
+			t.Fatalf("BetaFromMeanVar(%f, %f) error: %v", c.mean, c.variance, err)
+		}
+
+		total := alpha + beta
+		mean := alpha / total
+		variance := alpha * beta / (total * total * (total + 1))
+
+		if !equiv(mean, c.mean) || !equiv(variance, c.variance) {
+			t.Errorf("Beta(%f, %f) has mean %f and variance %f, want %f and %f",
+				alpha, beta, mean, variance, c.mean, c.variance)
+		}
+	}
+}
+
+func TestBetaFromMeanVarInfeasible(t *testing.T) {
+	for _, c := range []struct{ mean, variance float64 }{
+		{0.5, 0.25}, // variance must be below mean(1-mean)
+		{0.5, 0},
+		{0, 0.01},
+		{1.2, 0.01},
+	} {
+		if _, _, err := BetaFromMeanVar(c.mean, c.variance); err == nil {
+			t.Errorf("BetaFromMeanVar(%f, %f) succeeded, want error", c.mean, c.variance)
+		}
+	}
+}
+
+func TestFactorial(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:  "1",
+		1:  "1",
+		5:  "120",
+		20: "2432902008176640000",
+		25: "15511210043330985984000000",
+	} {
+		if got := Factorial(big.NewInt(n)); got.String() != want {
+			t.Errorf("Factorial(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	for _, n := range []int64{0, 7, 100} {
+		if got, want := Factorial(big.NewInt(n)), factorialRecursive(big.NewInt(n)); got.Cmp(want) != 0 {
+			t.Errorf("Factorial(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
+
+// factorialRecursive is the previous, recursive, implementation of
+// Factorial, for comparison
+func factorialRecursive(n *big.Int) *big.Int {
+	if n.Cmp(nint(0)) == 0 {
+		return big.NewInt(1)
+	}
+
+	i, z := nint(0), nint(0)
+
+	return z.Mul(n, factorialRecursive(i.Sub(n, nint(1))))
+}
+
+func BenchmarkFactorial(b *testing.B) {
+	n := big.NewInt(5000)
+
+	for i := 0; i < b.N; i++ {
+		Factorial(n)
+	}
+}
+
+func BenchmarkFactorialRecursive(b *testing.B) {
+	n := big.NewInt(5000)
+
+	for i := 0; i < b.N; i++ {
+		factorialRecursive(n)
+	}
+}
+
+func TestBinomialLargeN(t *testing.T) {
+	pmf := Binomial(100, 0.5)
+
+	sum := 0.0
+	for k := int64(0); k <= 100; k++ {
+		sum += float64(pmf(k))
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("Binomial(100, 0.5) sums to %f, want 1", sum)
+	}
+
+	// (100 choose 50) / 2^100
+	if p := pmf(50); !equiv(float64(p), 0.07958923738717877) {
+		t.Errorf("Binomial(100, 0.5)(50) = %f, want 0.079589", p)
+	}
+
+	if p := Binomial(5000, 0.3)(1500); p <= 0 || p >= 1 {
+		t.Errorf("Binomial(5000, 0.3)(1500) = %f, want on (0, 1)", p)
+	}
+}
+
+func TestPoissonLargeK(t *testing.T) {
+	pmf := Poisson(10)
+
+	sum := 0.0
+	for k := 0; k <= 60; k++ {
+		sum += float64(pmf(k))
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("Poisson(10) sums to %f, want 1", sum)
+	}
+
+	p := float64(pmf(30))
+	if math.IsInf(p, 0) || math.IsNaN(p) || p <= 0 {
+		t.Fatalf("Poisson(10)(30) = %g, want finite and positive", p)
+	}
+
+	// e^-10 10^30 / 30!
+	if want := 1.7115717355367894e-07; math.Abs(p-want) > 1e-9*want {
+		t.Errorf("Poisson(10)(30) = %g, want %g", p, want)
+	}
+}
+
+func TestMultinomial(t *testing.T) {
+	if m, b := Multinomial(0.5, 0.5)(2, 0), Binomial(2, 0.5)(2); !equiv(float64(m), float64(b)) {
+		t.Errorf("Multinomial(0.5, 0.5)(2, 0) = %f, want Binomial(2, 0.5)(2) = %f", m, b)
+	}
+
+	pmf := Multinomial(0.2, 0.3, 0.5)
+	const n = 6
+
+	sum := 0.0
+	for i := 0; i <= n; i++ {
+		for j := 0; i+j <= n; j++ {
+			sum += float64(pmf(i, j, n-i-j))
+		}
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("Multinomial(0.2, 0.3, 0.5) sums to %f over partitions of %d, want 1", sum, n)
+	}
+}
+
+func TestNegativeBinomial(t *testing.T) {
+	geometric, negative := Geometric(0.3), NegativeBinomial(1, 0.3)
+	for k := 1; k <= 10; k++ {
+		if g, n := geometric(k), negative(int64(k)); !equiv(float64(g), float64(n)) {
+			t.Errorf("NegativeBinomial(1, 0.3)(%d) = %f, want Geometric(0.3)(%d) = %f", k, n, k, g)
+		}
+	}
+
+	pmf := NegativeBinomial(3, 0.4)
+
+	sum := 0.0
+	for k := int64(0); k <= 200; k++ {
+		sum += float64(pmf(k))
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("NegativeBinomial(3, 0.4) sums to %f, want 1", sum)
+	}
+}
+
+func TestNegativeBinomialInvalid(t *testing.T) {
+	assertPanics(t, func() { NegativeBinomial(0, 0.5) })
+	assertPanics(t, func() { NegativeBinomial(2, 1.5) })
+}
+
+func TestHypergeometric(t *testing.T) {
+	// the number of aces in a five card poker hand
+	pmf := Hypergeometric(52, 4, 5)
+
+	// (4 choose 2)(48 choose 3)/(52 choose 5)
+	if p, want := pmf(2), 6.0*17296/2598960; !equiv(float64(p), want) {
+		t.Errorf("P(2 aces) = %f, want %f", p, want)
+	}
+
+	sum := 0.0
+	for k := int64(0); k <= 4; k++ {
+		sum += float64(pmf(k))
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("Hypergeometric(52, 4, 5) sums to %f, want 1", sum)
+	}
+
+	for _, k := range []int64{-1, 5} {
+		if p := pmf(k); p != Impossible {
+			t.Errorf("P(%d aces) = %f, want 0", k, p)
+		}
+	}
+
+	// drawing 50 of 52 cards, of which only 4 are failures, gives at least 46 successes
+	if p := Hypergeometric(52, 48, 50)(45); p != Impossible {
+		t.Errorf("Hypergeometric(52, 48, 50)(45) = %f, want 0", p)
+	}
+}
+
+func TestDiscreteUniform(t *testing.T) {
+	pmf := DiscreteUniform(-2, 2)
+
+	for k := -2; k <= 2; k++ {
+		if p := pmf(k); !equiv(float64(p), 0.2) {
+			t.Errorf("DiscreteUniform(-2, 2)(%d) = %f, want 0.2", k, p)
+		}
+	}
+
+	for _, k := range []int{-3, 3} {
+		if p := pmf(k); p != Impossible {
+			t.Errorf("DiscreteUniform(-2, 2)(%d) = %f, want 0", k, p)
+		}
+	}
+
+	for _, k := range []int{0, 7} {
+		if p := Uniform(6)(k); p != Impossible {
+			t.Errorf("Uniform(6)(%d) = %f, want 0", k, p)
+		}
+	}
+}
+
+func TestNewBernoulliAndBinomial(t *testing.T) {
+	if e := Expectation(NewBernoulli(0.3), value); !equiv(e, 0.3) {
+		t.Errorf("E[X] over NewBernoulli(0.3) = %f, want 0.3", e)
+	}
+
+	d := NewBinomial(10, 0.3)
+
+	if !FullySupported(d) {
+		t.Errorf("NewBinomial(10, 0.3) is not fully supported")
+	}
+
+	if e := Expectation(d, value); !equiv(e, 3) {
+		t.Errorf("E[X] over NewBinomial(10, 0.3) = %f, want 3", e)
+	}
+}
+
+func TestLogFactorial(t *testing.T) {
+	// 170! exceeds an int64, but not a float64
+	if Factorial(big.NewInt(170)).IsInt64() {
+		t.Fatalf("170! fits in an int64")
+	}
+
+	lf := LogFactorial(170)
+	if math.IsInf(lf, 0) || math.IsNaN(lf) {
+		t.Fatalf("LogFactorial(170) = %f, want finite", lf)
+	}
+
+	want, _ := new(big.Float).SetInt(Factorial(big.NewInt(170))).Float64()
+	if math.Abs(lf-math.Log(want)) > 1e-9*math.Log(want) {
+		t.Errorf("LogFactorial(170) = %f, want %f", lf, math.Log(want))
+	}
+
+	if lf := LogFactorial(5); !equiv(lf, math.Log(120)) {
+		t.Errorf("LogFactorial(5) = %f, want %f", lf, math.Log(120))
+	}
+}
+
+func TestGeometricConventions(t *testing.T) {
+	trials, failures := Geometric(0.3), GeometricFailures(0.3)
+
+	for k := -1; k <= 10; k++ {
+		if f, g := failures(k), trials(k+1); !equiv(float64(f), float64(g)) {
+			t.Errorf("GeometricFailures(0.3)(%d) = %f, want Geometric(0.3)(%d) = %f", k, f, k+1, g)
+		}
+	}
+
+	if p := trials(-1); p != Impossible {
+		t.Errorf("Geometric(0.3)(-1) = %f, want Impossible", p)
+	}
+
+	sumTrials, sumFailures := 0.0, 0.0
+	for k := -1; k <= 100; k++ {
+		sumTrials += float64(trials(k))
+		sumFailures += float64(failures(k))
+	}
+
+	if !equiv(sumTrials, 1) || !equiv(sumFailures, 1) {
+		t.Errorf("Geometric(0.3) sums to %f, GeometricFailures(0.3) to %f, want 1", sumTrials, sumFailures)
+	}
+}
+
+func TestGeometricCDF(t *testing.T) {
+	pmf, cdf := Geometric(0.3), GeometricCDF(0.3)
+
+	sum := 0.0
+	for k := 1; k <= 30; k++ {
+		sum += float64(pmf(k))
+
+		if c := cdf(k); !equiv(float64(c), sum) {
+			t.Errorf("GeometricCDF(0.3)(%d) = %f, want the summed PMF %f", k, c, sum)
+		}
+	}
+
+	if c := cdf(0); c != Impossible {
+		t.Errorf("GeometricCDF(0.3)(0) = %f, want 0", c)
+	}
+}
+
+func TestPMFExpectation(t *testing.T) {
+	const n, p = 20, 0.3
+
+	binomial := Binomial(n, p)
+	pmf := func(k int) Probability { return binomial(int64(k)) }
+
+	support := make([]int, n+1)
+	for k := range support {
+		support[k] = k
+	}
+
+	if e := PMFExpectation(pmf, support); !equiv(e, n*p) {
+		t.Errorf("PMFExpectation(Binomial(%d, %f)) = %f, want %f", n, p, e, n*p)
+	}
+
+	if v := PMFVariance(pmf, support); !equiv(v, n*p*(1-p)) {
+		t.Errorf("PMFVariance(Binomial(%d, %f)) = %f, want %f", n, p, v, n*p*(1-p))
+	}
+}
+
+func TestBetaBinomial(t *testing.T) {
+	const n = 10
+
+	uniform := BetaBinomial(n, 1, 1)
+	for k := int64(0); k <= n; k++ {
+		if p := uniform(k); !equiv(float64(p), 1.0/(n+1)) {
+			t.Errorf("BetaBinomial(%d, 1, 1)(%d) = %f, want %f", n, k, p, 1.0/(n+1))
+		}
+	}
+
+	pmf := BetaBinomial(n, 2, 5)
+
+	sum := 0.0
+	for k := int64(0); k <= n; k++ {
+		sum += float64(pmf(k))
+	}
+
+	if !equiv(sum, 1) {
+		t.Errorf("BetaBinomial(%d, 2, 5) sums to %f, want 1", n, sum)
+	}
+}
+
+func TestSumsToOne(t *testing.T) {
+	support := []int{0, 1, 2, 3, 4, 5}
+
+	binomial := Binomial(5, 0.4)
+	if !SumsToOne(func(k int) Probability { return binomial(int64(k)) }, support) {
+		t.Errorf("Binomial(5, 0.4) does not sum to one")
+	}
+
+	malformed := func(k int) Probability { return 0.2 }
+	if SumsToOne(malformed, support) {
+		t.Errorf("constant 0.2 over 6 outcomes sums to one")
+	}
+
+	// the support must cover the mass
+	if SumsToOne(func(k int) Probability { return binomial(int64(k)) }, support[:3]) {
+		t.Errorf("Binomial(5, 0.4) sums to one over {0, 1, 2}")
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	for _, n := range []int64{2, 10, 100} {
+		if k := BinomialQuantile(n, 0.5)(0.5); k != n/2 {
+			t.Errorf("BinomialQuantile(%d, 0.5)(0.5) = %d, want %d", n, k, n/2)
+		}
+	}
+
+	cdf, quantile := GeometricCDF(0.3), GeometricQuantile(0.3)
+	for _, q := range []Probability{0, 0.1, 0.3, 0.5, 0.51, 0.9, 0.999} {
+		k := quantile(q)
+
+		if c := cdf(k); float64(c) <= float64(q)-Epsilon() {
+			t.Errorf("GeometricCDF(0.3)(%d) = %f, want at least %f", k, c, q)
+		}
+
+		if c := cdf(k - 1); k > 1 && float64(c) > float64(q)-Epsilon() {
+			t.Errorf("GeometricQuantile(0.3)(%f) = %d, but GeometricCDF(0.3)(%d) = %f", q, k, k-1, c)
+		}
+	}
+
+	if k := GeometricQuantile(1)(0.9); k != 1 {
+		t.Errorf("GeometricQuantile(1)(0.9) = %d, want 1", k)
+	}
+
+	if k := PoissonQuantile(0)(0.9); k != 0 {
+		t.Errorf("PoissonQuantile(0)(0.9) = %d, want 0", k)
+	}
+
+	// P(X ≤ 2) = 0.4232, P(X ≤ 3) = 0.6472, for mu = 3
+	if k := PoissonQuantile(3)(0.5); k != 3 {
+		t.Errorf("PoissonQuantile(3)(0.5) = %d, want 3", k)
+	}
+}
+
+func TestQuantilesSmallEpsilon(t *testing.T) {
+	defer SetEpsilon(Epsilon())
+	SetEpsilon(1e-20)
+
+	// each must terminate, though the cumulative probability can not
+	// resolve q to within epsilon
+	q := Probability(1 - 1e-16)
+
+	if k := GeometricQuantile(0.3)(q); k < 1 {
+		t.Errorf("GeometricQuantile(0.3)(%g) = %d, want positive", q, k)
+	}
+
+	if k := PoissonQuantile(10)(q); k <= 10 {
+		t.Errorf("PoissonQuantile(10)(%g) = %d, want beyond the mean", q, k)
+	}
+
+	if k := BinomialQuantile(20, 0.5)(q); k > 20 {
+		t.Errorf("BinomialQuantile(20, 0.5)(%g) = %d, want at most 20", q, k)
+	}
+}
+
+func TestQuantilesInvalid(t *testing.T) {
+	assertPanics(t, func() { GeometricQuantile(0) })
+	assertPanics(t, func() { GeometricQuantile(1.5) })
+	assertPanics(t, func() { GeometricQuantile(0.3)(1) })
+	assertPanics(t, func() { PoissonQuantile(-1) })
+	assertPanics(t, func() { PoissonQuantile(3)(1) })
+	assertPanics(t, func() { BinomialQuantile(10, 1.5) })
+}
+
+func TestMemoizePMF(t *testing.T) {
+	calls := 0
+	poisson := Poisson(10)
+	pmf := MemoizePMF(func(k int) Probability {
+		calls++
+		return poisson(k)
+	})
+
+	for i := 0; i < 3; i++ {
+		for k := 0; k <= 30; k++ {
+			if m, p := pmf(k), poisson(k); m != p {
+				t.Errorf("memoized Poisson(10)(%d) = %g, want %g", k, m, p)
+			}
+		}
+	}
+
+	if calls != 31 {
+		t.Errorf("pmf evaluated %d times, want once for each of 31 k", calls)
+	}
+}
+
+func BenchmarkPoisson(b *testing.B) {
+	pmf := Poisson(10)
+
+	for i := 0; i < b.N; i++ {
+		pmf(i % 50)
+	}
+}
+
+func BenchmarkPoissonMemoized(b *testing.B) {
+	pmf := MemoizePMF(Poisson(10))
+
+	for i := 0; i < b.N; i++ {
+		pmf(i % 50)
+	}
+}
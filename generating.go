@@ -0,0 +1,60 @@
+package prob
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// --- Probability Generating Function {{{
+
+// PGF constructs the probability generating function of a
+// distribution over integer outcomes.
+//
+// Recall: G(z) = E[z^X] = Σ_k P(X = k) z^k
+//
+// So G(1) = 1, and G'(1) = E[X]. The PGF of a sum of independent
+// integer random variables is the product of their PGFs.
+func PGF(d DiscreteDistribution) func(z float64) float64 {
+	return func(z float64) float64 {
+		g := 0.0
+
+		for _, o := range d.Outcomes().Elements() {
+			g += float64(d.ProbabilityOf(o)) * math.Pow(z, float64(integer(o)))
+		}
+
+		return g
+	}
+}
+
+// --- }}}
+
+// --- Moment Generating Function {{{
+
+// MGF evaluates the moment generating function of a random variable,
+// X over a distribution d, at t.
+//
+// Recall: M(t) = E[e^{tX}]
+//
+// So M(0) = 1, and the nth derivative of M at 0 is the nth moment of X.
+func MGF(d Distribution, X RandomVariable, t float64) float64 {
+	return Expectation(d, func(o Outcome) float64 {
+		return math.Exp(t * X(o))
+	})
+}
+
+// CharacteristicFunction evaluates the characteristic function of a
+// random variable, X over a distribution d, at t. Unlike the MGF, it
+// always exists.
+//
+// Recall: φ(t) = E[e^{itX}]
+func CharacteristicFunction(d Distribution, X RandomVariable, t float64) complex128 {
+	var phi complex128
+
+	for _, o := range d.Outcomes().Elements() {
+		phi += cmplx.Exp(complex(0, t*X(o))) * complex(float64(d.ProbabilityOf(o)), 0)
+	}
+
+	return phi
+}
+
+// --- }}}
@@ -0,0 +1,61 @@
+package prob
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+func TestPGF(t *testing.T) {
+	G := PGF(die())
+
+	if g := G(1); !equiv(g, 1) {
+		t.Errorf("G(1) = %f, want 1", g)
+	}
+
+	// central difference approximation of G'(1)
+	const h = 1e-6
+	if dG := (G(1+h) - G(1-h)) / (2 * h); math.Abs(dG-3.5) > 1e-4 {
+		t.Errorf("G'(1) = %f, want E[X] = 3.5", dG)
+	}
+}
+
+func TestPGFOfSum(t *testing.T) {
+	d := die()
+
+	// the sum of two fair dice: P(S = s) = (6 - |s - 7|) / 36
+	sums := set.New()
+	masses := make(map[Outcome]Probability)
+	for s := 2; s <= 12; s++ {
+		sums.Add(s)
+		masses[s] = Probability(6-math.Abs(float64(s-7))) / 36
+	}
+	sum := newDistribution(sums, masses)
+
+	G, Gsum := PGF(d), PGF(sum)
+
+	for _, z := range []float64{0, 0.5, 1, 1.5} {
+		if !equiv(Gsum(z), G(z)*G(z)) {
+			t.Errorf("G_{X+Y}(%f) = %f, want G_X(%f)G_Y(%f) = %f", z, Gsum(z), z, z, G(z)*G(z))
+		}
+	}
+}
+
+func TestMGF(t *testing.T) {
+	d := die()
+
+	if m := MGF(d, value, 0); !equiv(m, 1) {
+		t.Errorf("M(0) = %f, want 1", m)
+	}
+
+	// central difference approximation of M'(0)
+	const h = 1e-6
+	if dM := (MGF(d, value, h) - MGF(d, value, -h)) / (2 * h); math.Abs(dM-3.5) > 1e-4 {
+		t.Errorf("M'(0) = %f, want E[X] = 3.5", dM)
+	}
+
+	if phi := CharacteristicFunction(d, value, 0); !equiv(real(phi), 1) || !equiv(imag(phi), 0) {
+		t.Errorf("φ(0) = %v, want 1", phi)
+	}
+}
@@ -0,0 +1,240 @@
+package prob
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nlandolfi/set"
+)
+
+// --- Likelihood {{{
+
+// LogProbabilityOf computes the (natural) log of the probability of
+// the outcome o under the distribution d, or -Inf if o is impossible.
+// Sums of log-probabilities do not underflow, as products of small
+// probabilities do.
+func LogProbabilityOf(d Distribution, o Outcome) float64 {
+	return math.Log(float64(d.ProbabilityOf(o)))
+}
+
+// LogLikelihood computes the (natural) log-likelihood of the observed
+// data under the distribution d, assuming the observations are
+// independent.
+//
+// Recall: log L(d) = Σ_i log P(x_i)
+//
+// If any observation is impossible under d, the log-likelihood is -Inf.
+func LogLikelihood(d Distribution, data Outcomes) float64 {
+	ll := 0.0
+
+	for _, x := range data {
+		ll += LogProbabilityOf(d, x)
+	}
+
+	return ll
+}
+
+// AIC computes the Akaike information criterion of a model with the
+// given log-likelihood and number of parameters. Lower is better.
+//
+// Recall: AIC = 2k - 2 log L
+func AIC(logLik float64, numParams int) float64 {
+	return 2*float64(numParams) - 2*logLik
+}
+
+// BIC computes the Bayesian information criterion of a model with the
+// given log-likelihood and number of parameters, fit to n observations.
+// Lower is better.
+//
+// Recall: BIC = k log(n) - 2 log L
+func BIC(logLik float64, numParams, n int) float64 {
+	return float64(numParams)*math.Log(float64(n)) - 2*logLik
+}
+
+// --- }}}
+
+// --- Goodness of Fit {{{
+
+// ChiSquaredStatistic computes Pearson's chi-squared statistic for the
+// observed counts of each outcome against the distribution d. Outcomes
+// of d which were not observed have a count of 0.
+//
+// Recall: χ² = Σ_i (O_i - E_i)^2 / E_i, where E_i = n P(o_i)
+//
+// Every observed outcome must have non-zero probability under d.
+func ChiSquaredStatistic(observed map[Outcome]int, d Distribution) float64 {
+	total := 0
+	for o, count := range observed {
+		assert(d.ProbabilityOf(o) > Impossible, "observed outcome not supported")
+		total += count
+	}
+
+	chi := 0.0
+
+	for _, o := range d.Outcomes().Elements() {
+		expected := float64(total) * float64(d.ProbabilityOf(o))
+		if expected == 0 {
+			continue
+		}
+
+		chi += math.Pow(float64(observed[o])-expected, 2) / expected
+	}
+
+	return chi
+}
+
+// --- }}}
+
+// --- Bayesian Updating {{{
+
+// Posterior computes the posterior distribution given the prior
+// distribution and the likelihood of some observed evidence, e, for
+// each outcome. The prior mass of each outcome is multiplied by its
+// likelihood, and the result renormalized.
+//
+// Recall: P(o | e) = P(e | o)P(o) / P(e), where P(e) = Σ_o P(e | o)P(o)
+//
+// The evidence must have non-zero probability under the prior.
+func Posterior(prior DiscreteDistribution, likelihood func(Outcome) Probability) DiscreteDistribution {
+	d, err := posterior(prior, likelihood)
+	assert(err == nil, "evidence has zero probability")
+
+	return d
+}
+
+// posterior computes the posterior distribution, see Posterior. It
+// returns an error if the evidence has zero probability.
+func posterior(prior Distribution, likelihood func(Outcome) Probability) (*distribution, error) {
+	masses := make(map[Outcome]Probability)
+	evidence := Impossible
+
+	for _, o := range prior.Outcomes().Elements() {
+		p := prior.ProbabilityOf(o) * likelihood(o)
+		masses[o] = p
+		evidence += p
+	}
+
+	if evidence == Impossible {
+		return nil, errors.New("prob: evidence has zero probability")
+	}
+
+	for o := range masses {
+		masses[o] /= evidence
+	}
+
+	return newDistribution(prior.Domain(), masses), nil
+}
+
+// An UpdatableBelief is a distribution of beliefs over a domain
+// which accumulates evidence over time. It begins as a prior, and
+// each Update conditions the current beliefs on new evidence. This
+// is the streaming analog of Posterior.
+//
+// An UpdatableBelief is itself a Distribution, so the current
+// beliefs can be examined with Expectation, Variance, etc.
+type UpdatableBelief struct {
+	belief *distribution
+}
+
+// NewUpdatableBelief constructs an UpdatableBelief, starting from
+// the prior distribution. The prior must be fully supported.
+func NewUpdatableBelief(prior DiscreteDistribution) *UpdatableBelief {
+	if checks && !FullySupported(prior) {
+		panic("prior not fully supported")
+	}
+
+	masses := make(map[Outcome]Probability)
+	for _, o := range prior.Outcomes().Elements() {
+		masses[o] = prior.ProbabilityOf(o)
+	}
+
+	return &UpdatableBelief{
+		belief: newDistribution(prior.Domain(), masses),
+	}
+}
+
+func (b *UpdatableBelief) Domain() set.AbstractInterface {
+	return b.belief.Domain()
+}
+
+func (b *UpdatableBelief) Outcomes() set.Interface {
+	return b.belief.Outcomes()
+}
+
+func (b *UpdatableBelief) ProbabilityOf(o Outcome) Probability {
+	return b.belief.ProbabilityOf(o)
+}
+
+// Update multiplies the current beliefs by the likelihood of the
+// observed evidence, and renormalizes. See Posterior.
+//
+// If the evidence has zero probability under the current beliefs,
+// Update returns an error, and the beliefs are left unchanged.
+func (b *UpdatableBelief) Update(likelihood func(Outcome) Probability) error {
+	belief, err := posterior(b.belief, likelihood)
+	if err != nil {
+		return err
+	}
+
+	b.belief = belief
+
+	return nil
+}
+
+// --- }}}
+
+// --- Mixtures {{{
+
+// Responsibilities computes, for an observed outcome o of a mixture
+// of the distributions dists with the given weights, the posterior
+// probability that each component generated o.
+//
+// Recall: r_i = w_i P_i(o) / Σ_j w_j P_j(o)
+//
+// This is the E-step of fitting a mixture by expectation maximization.
+func Responsibilities(weights []Probability, dists []DiscreteDistribution, o Outcome) []Probability {
+	assert(len(weights) == len(dists), "weights and distributions differ in length")
+
+	rs := make([]Probability, len(dists))
+	total := Impossible
+
+	for i, d := range dists {
+		rs[i] = weights[i] * d.ProbabilityOf(o)
+		total += rs[i]
+	}
+
+	assert(total != Impossible, "outcome impossible under mixture")
+
+	for i := range rs {
+		rs[i] /= total
+	}
+
+	return rs
+}
+
+// EMUpdateWeights performs one iteration of expectation maximization
+// for the weights of a mixture of the distributions dists, given the
+// observed data. Each new weight is the average responsibility of its
+// component for the data.
+//
+// Repeated application does not decrease the log-likelihood of the
+// data under the mixture.
+func EMUpdateWeights(dists []DiscreteDistribution, currentWeights []Probability, data Outcomes) []Probability {
+	assert(len(data) > 0, "no data")
+
+	weights := make([]Probability, len(dists))
+
+	for _, o := range data {
+		for i, r := range Responsibilities(currentWeights, dists, o) {
+			weights[i] += r
+		}
+	}
+
+	for i := range weights {
+		weights[i] /= Probability(len(data))
+	}
+
+	return weights
+}
+
+// --- }}}
@@ -0,0 +1,244 @@
+package prob
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+// coins constructs a prior over a fair and a biased coin, and the
+// likelihood of observing heads with each
+func coins() (DiscreteDistribution, func(Outcome) Probability) {
+	prior := NewUniformDiscrete(set.WithElements("fair", "biased"))
+
+	heads := func(o Outcome) Probability {
+		if o == "fair" {
+			return 0.5
+		}
+
+		return 0.9
+	}
+
+	return prior, heads
+}
+
+func TestUpdatableBelief(t *testing.T) {
+	prior, heads := coins()
+	tails := func(o Outcome) Probability {
+		return 1 - heads(o)
+	}
+
+	b := NewUpdatableBelief(prior)
+	for _, likelihood := range []func(Outcome) Probability{heads, heads, tails} {
+		if err := b.Update(likelihood); err != nil {
+			t.Fatalf("Update error: %v", err)
+		}
+	}
+
+	// the batch posterior is proportional to P(o)P(H | o)P(H | o)P(T | o)
+	fair, biased := 0.5*0.5*0.5, 0.9*0.9*0.1
+	if p, want := b.ProbabilityOf("fair"), fair/(fair+biased); !equiv(float64(p), want) {
+		t.Errorf("P(fair) = %f, want %f", p, want)
+	}
+
+	if p, want := b.ProbabilityOf("biased"), biased/(fair+biased); !equiv(float64(p), want) {
+		t.Errorf("P(biased) = %f, want %f", p, want)
+	}
+
+	if !FullySupported(b) {
+		t.Errorf("belief not fully supported after updates")
+	}
+}
+
+func TestUpdatableBeliefImpossibleEvidence(t *testing.T) {
+	prior, _ := coins()
+	b := NewUpdatableBelief(prior)
+
+	err := b.Update(func(Outcome) Probability { return Impossible })
+	if err == nil {
+		t.Fatalf("Update with impossible evidence succeeded, want error")
+	}
+
+	if !strings.HasPrefix(err.Error(), "prob: ") {
+		t.Errorf("Update error = %q, want the prob: prefix", err)
+	}
+
+	for _, o := range prior.Outcomes().Elements() {
+		if p := b.ProbabilityOf(o); p != prior.ProbabilityOf(o) {
+			t.Errorf("P(%v) = %f after impossible evidence, want %f", o, p, prior.ProbabilityOf(o))
+		}
+	}
+}
+
+func TestResponsibilities(t *testing.T) {
+	low := newDistribution(die().Outcomes(), map[Outcome]Probability{
+		1: 5.0 / 18, 2: 5.0 / 18, 3: 5.0 / 18, 4: 1.0 / 18, 5: 1.0 / 18, 6: 1.0 / 18,
+	})
+	high := newDistribution(die().Outcomes(), map[Outcome]Probability{
+		1: 1.0 / 18, 2: 1.0 / 18, 3: 1.0 / 18, 4: 5.0 / 18, 5: 5.0 / 18, 6: 5.0 / 18,
+	})
+	dists := []DiscreteDistribution{low, high}
+	weights := []Probability{0.5, 0.5}
+
+	for _, o := range []Outcome{1, 6} {
+		rs := Responsibilities(weights, dists, o)
+
+		if sum := rs[0] + rs[1]; !equiv(float64(sum), 1) {
+			t.Errorf("responsibilities for %v sum to %f, want 1", o, sum)
+		}
+	}
+
+	if rs := Responsibilities(weights, dists, 6); rs[1] <= rs[0] || !equiv(float64(rs[1]), 5.0/6) {
+		t.Errorf("responsibilities for 6 = %v, want concentrated on the high component, 5/6", rs)
+	}
+}
+
+func TestEMUpdateWeights(t *testing.T) {
+	low := newDistribution(die().Outcomes(), map[Outcome]Probability{
+		1: 5.0 / 18, 2: 5.0 / 18, 3: 5.0 / 18, 4: 1.0 / 18, 5: 1.0 / 18, 6: 1.0 / 18,
+	})
+	high := newDistribution(die().Outcomes(), map[Outcome]Probability{
+		1: 1.0 / 18, 2: 1.0 / 18, 3: 1.0 / 18, 4: 5.0 / 18, 5: 5.0 / 18, 6: 5.0 / 18,
+	})
+	dists := []DiscreteDistribution{low, high}
+	r := rand.New(rand.NewSource(1))
+
+	// the data are drawn from low w.p. 0.8, and from high w.p. 0.2
+	data := make(Outcomes, 1000)
+	for i := range data {
+		if r.Float64() < 0.8 {
+			data[i] = SimulateWith(low, r)
+		} else {
+			data[i] = SimulateWith(high, r)
+		}
+	}
+
+	// the log-likelihood of the data under the mixture with weights
+	logLikelihood := func(weights []Probability) float64 {
+		ll := 0.0
+		for _, o := range data {
+			p := 0.0
+			for i, d := range dists {
+				p += float64(weights[i] * d.ProbabilityOf(o))
+			}
+			ll += math.Log(p)
+		}
+		return ll
+	}
+
+	weights := []Probability{0.5, 0.5}
+	ll := logLikelihood(weights)
+
+	for i := 0; i < 10; i++ {
+		weights = EMUpdateWeights(dists, weights, data)
+
+		next := logLikelihood(weights)
+		if next < ll-epsilon {
+			t.Fatalf("iteration %d: log-likelihood decreased from %f to %f", i, ll, next)
+		}
+
+		ll = next
+	}
+
+	if sum := weights[0] + weights[1]; !equiv(float64(sum), 1) {
+		t.Errorf("weights sum to %f, want 1", sum)
+	}
+
+	if math.Abs(float64(weights[0])-0.8) > 0.1 {
+		t.Errorf("fitted weights = %v, want near [0.8 0.2]", weights)
+	}
+}
+
+func TestLogLikelihood(t *testing.T) {
+	data := Outcomes{6, 6, 5, 6, 1, 6, 4, 6}
+	fair := die()
+	loaded := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 5})
+
+	if ll, want := LogLikelihood(fair, data), 8*math.Log(1.0/6); !equiv(ll, want) {
+		t.Errorf("LogLikelihood(fair) = %f, want %f", ll, want)
+	}
+
+	if LogLikelihood(loaded, data) <= LogLikelihood(fair, data) {
+		t.Errorf("loaded die does not score higher on data favoring 6")
+	}
+
+	never := NewCategorical(Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1, 0})
+	if ll := LogLikelihood(never, data); !math.IsInf(ll, -1) {
+		t.Errorf("LogLikelihood with an impossible datum = %f, want -Inf", ll)
+	}
+}
+
+func TestAICAndBIC(t *testing.T) {
+	// AIC = 2k - 2 log L = 2(3) - 2(-10)
+	if aic := AIC(-10, 3); !equiv(aic, 26) {
+		t.Errorf("AIC(-10, 3) = %f, want 26", aic)
+	}
+
+	// BIC = k log(n) - 2 log L = 3 log(100) - 2(-10)
+	if bic := BIC(-10, 3, 100); !equiv(bic, 33.815510557964274) {
+		t.Errorf("BIC(-10, 3, 100) = %f, want 33.815511", bic)
+	}
+}
+
+func TestPosterior(t *testing.T) {
+	prior, heads := coins()
+	post := Posterior(prior, heads)
+
+	// P(biased | H) = 0.9(0.5) / (0.9(0.5) + 0.5(0.5)) = 9/14
+	if p := post.ProbabilityOf("biased"); !equiv(float64(p), 9.0/14) {
+		t.Errorf("P(biased | H) = %f, want 9/14", p)
+	}
+
+	if p := post.ProbabilityOf("fair"); !equiv(float64(p), 5.0/14) {
+		t.Errorf("P(fair | H) = %f, want 5/14", p)
+	}
+}
+
+func TestPosteriorImpossibleEvidence(t *testing.T) {
+	prior, _ := coins()
+
+	assertPanics(t, func() {
+		Posterior(prior, func(Outcome) Probability { return Impossible })
+	})
+}
+
+func TestChiSquaredStatistic(t *testing.T) {
+	d := die()
+
+	match := make(map[Outcome]int)
+	for face := 1; face <= 6; face++ {
+		match[face] = 10
+	}
+
+	if chi := ChiSquaredStatistic(match, d); chi != 0 {
+		t.Errorf("χ² = %f, want 0 for counts matching their expectation", chi)
+	}
+
+	mismatch := map[Outcome]int{1: 20, 2: 8, 3: 8, 4: 8, 5: 8, 6: 8}
+
+	// (20 - 10)^2/10 + 5 (8 - 10)^2/10
+	if chi := ChiSquaredStatistic(mismatch, d); !equiv(chi, 12) {
+		t.Errorf("χ² = %f, want 12", chi)
+	}
+}
+
+func TestLogLikelihoodOfProduct(t *testing.T) {
+	d := NewCategorical(Outcomes{1, 2, 3}, []float64{0.5, 0.3, 0.2})
+	data := Outcomes{1, 1, 2, 3, 1, 2}
+
+	product := 1.0
+	for _, x := range data {
+		product *= float64(d.ProbabilityOf(x))
+	}
+
+	if ll := LogLikelihood(d, data); !equiv(ll, math.Log(product)) {
+		t.Errorf("LogLikelihood = %f, want log of the product %f", ll, math.Log(product))
+	}
+
+	if lp := LogProbabilityOf(d, 2); !equiv(lp, math.Log(0.3)) {
+		t.Errorf("LogProbabilityOf(d, 2) = %f, want %f", lp, math.Log(0.3))
+	}
+}
@@ -0,0 +1,281 @@
+package prob
+
+import (
+	"math"
+
+	"github.com/nlandolfi/set"
+)
+
+// --- Entropy {{{
+
+// Entropy computes the Shannon entropy of a distribution d, in bits.
+//
+// Recall: H(d) = -Σ p(o) log2 p(o)
+//
+// So, the uniform distribution over n outcomes has entropy log2(n),
+// and a degenerate distribution has entropy 0.
+func Entropy(d Distribution) float64 {
+	return EntropyBase(d, 2)
+}
+
+// EntropyBase computes the Shannon entropy of a distribution d, with
+// logarithms in the given base. For example, base e gives the entropy
+// in nats.
+func EntropyBase(d Distribution, base float64) float64 {
+	h := 0.0
+
+	for _, o := range d.Outcomes().Elements() {
+		p := float64(d.ProbabilityOf(o))
+		if p == 0 {
+			continue // by convention, 0 log 0 = 0
+		}
+
+		h -= p * math.Log(p)
+	}
+
+	return h / math.Log(base)
+}
+
+// RenyiEntropy computes the Rényi entropy of order alpha ≥ 0 of a
+// distribution d, in bits. It generalizes the Shannon entropy, which
+// is the limit as alpha → 1, and is used in its place there.
+//
+// Recall: H_α(d) = log2(Σ p(o)^α) / (1 - α)
+//
+// So, the uniform distribution over n outcomes has Rényi entropy
+// log2(n), of every order.
+func RenyiEntropy(d Distribution, alpha float64) float64 {
+	assert(alpha >= 0, "order must be non-negative")
+
+	if equiv(alpha, 1) {
+		return Entropy(d)
+	}
+
+	sum := 0.0
+
+	for _, o := range d.Outcomes().Elements() {
+		p := float64(d.ProbabilityOf(o))
+		if p == 0 {
+			continue
+		}
+
+		sum += math.Pow(p, alpha)
+	}
+
+	return math.Log2(sum) / (1 - alpha)
+}
+
+// JointEntropy computes the joint entropy of the random variables X
+// and Y over the distribution d, in bits.
+//
+// Recall: H(X, Y) = -Σ P(X = x, Y = y) log2 P(X = x, Y = y)
+//
+// If X and Y are independent, H(X, Y) = H(X) + H(Y).
+func JointEntropy(d DiscreteDistribution, X, Y RandomVariable) float64 {
+	masses := make(map[[2]float64]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		masses[[2]float64{X(o), Y(o)}] += d.ProbabilityOf(o)
+	}
+
+	h := 0.0
+	for _, p := range masses {
+		h -= xlog2x(float64(p))
+	}
+
+	return h
+}
+
+// ConditionalEntropy computes the entropy of the random variable Y
+// conditioned on X, over the distribution d, in bits. It satisfies the
+// chain rule: H(X, Y) = H(X) + H(Y | X).
+//
+// Recall: H(Y | X) = -Σ P(X = x, Y = y) log2 P(Y = y | X = x)
+func ConditionalEntropy(d DiscreteDistribution, X, Y RandomVariable) float64 {
+	return JointEntropy(d, X, Y) - variableEntropy(d, X)
+}
+
+// variableEntropy computes the entropy of the random variable X over
+// the distribution d, in bits
+func variableEntropy(d DiscreteDistribution, X RandomVariable) float64 {
+	masses := make(map[float64]Probability)
+
+	for _, o := range d.Outcomes().Elements() {
+		masses[X(o)] += d.ProbabilityOf(o)
+	}
+
+	h := 0.0
+	for _, p := range masses {
+		h -= xlog2x(float64(p))
+	}
+
+	return h
+}
+
+// xlog2x computes x*log2(x), taking 0*log2(0) to be 0
+func xlog2x(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+
+	return x * math.Log2(x)
+}
+
+// --- }}}
+
+// --- Divergence {{{
+
+// KLDivergence computes the Kullback-Leibler divergence of q from p,
+// in bits. The domains of p and q must be equivalent.
+//
+// Recall: D(p || q) = Σ p(o) log2(p(o)/q(o))
+//
+// The divergence is non-negative, and zero iff p and q are equal. If
+// q assigns no mass to an outcome which p supports, it is +Inf.
+func KLDivergence(p, q Distribution) float64 {
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
+
+	kl := 0.0
+
+	for _, o := range p.Outcomes().Elements() {
+		po, qo := float64(p.ProbabilityOf(o)), float64(q.ProbabilityOf(o))
+		if po == 0 {
+			continue
+		}
+
+		if qo == 0 {
+			return math.Inf(1)
+		}
+
+		kl += po * math.Log2(po/qo)
+	}
+
+	return kl
+}
+
+// RenyiDivergence computes the Rényi divergence of order alpha ≥ 0 of
+// q from p, in bits. It generalizes the Kullback-Leibler divergence,
+// which is the limit as alpha → 1, and is used in its place there. The
+// domains of p and q must be equivalent.
+//
+// Recall: D_α(p || q) = log2(Σ p(o)^α q(o)^(1-α)) / (α - 1)
+//
+// If alpha > 1, and q assigns no mass to an outcome which p supports,
+// it is +Inf.
+func RenyiDivergence(p, q Distribution, alpha float64) float64 {
+	assert(alpha >= 0, "order must be non-negative")
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
+
+	if equiv(alpha, 1) {
+		return KLDivergence(p, q)
+	}
+
+	sum := 0.0
+
+	for _, o := range p.Outcomes().Elements() {
+		po, qo := float64(p.ProbabilityOf(o)), float64(q.ProbabilityOf(o))
+		if po == 0 {
+			continue
+		}
+
+		if qo == 0 {
+			if alpha > 1 {
+				return math.Inf(1)
+			}
+
+			continue
+		}
+
+		sum += math.Pow(po, alpha) * math.Pow(qo, 1-alpha)
+	}
+
+	return math.Log2(sum) / (alpha - 1)
+}
+
+// CrossEntropy computes the cross entropy of q relative to p, in bits.
+// The domains of p and q must be equivalent.
+//
+// Recall: H(p, q) = -Σ p(o) log2 q(o) = H(p) + D(p || q)
+func CrossEntropy(p, q Distribution) float64 {
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
+
+	h := 0.0
+
+	for _, o := range p.Outcomes().Elements() {
+		po, qo := float64(p.ProbabilityOf(o)), float64(q.ProbabilityOf(o))
+		if po == 0 {
+			continue
+		}
+
+		if qo == 0 {
+			return math.Inf(1)
+		}
+
+		h -= po * math.Log2(qo)
+	}
+
+	return h
+}
+
+// --- }}}
+
+// --- Distance {{{
+
+// TotalVariationDistance computes the total variation distance between
+// the distributions p and q, which lies on the interval [0, 1]. The
+// domains of p and q must be equivalent.
+//
+// Recall: δ(p, q) = 1/2 Σ |p(o) - q(o)|
+func TotalVariationDistance(p, q Distribution) float64 {
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
+
+	tv := 0.0
+
+	for _, o := range set.Union(p.Outcomes(), q.Outcomes()).Elements() {
+		tv += math.Abs(float64(p.ProbabilityOf(o) - q.ProbabilityOf(o)))
+	}
+
+	return math.Min(tv/2, 1)
+}
+
+// BhattacharyyaCoefficient computes the Bhattacharyya coefficient of
+// the distributions p and q, which lies on the interval [0, 1]; it is
+// 1 iff p and q are equal. The domains of p and q must be equivalent.
+//
+// Recall: BC(p, q) = Σ sqrt(p(o)q(o))
+func BhattacharyyaCoefficient(p, q Distribution) float64 {
+	if checks && !equivalentDomains(p, q) {
+		panic("domains of both distributions must be equivalent")
+	}
+
+	bc := 0.0
+
+	for _, o := range p.Outcomes().Elements() {
+		if !q.Outcomes().Contains(o) {
+			continue
+		}
+
+		bc += math.Sqrt(float64(p.ProbabilityOf(o) * q.ProbabilityOf(o)))
+	}
+
+	return math.Min(bc, 1)
+}
+
+// HellingerDistance computes the Hellinger distance between the
+// distributions p and q, which lies on the interval [0, 1]. The
+// domains of p and q must be equivalent.
+//
+// Recall: H(p, q) = sqrt(1 - BC(p, q))
+func HellingerDistance(p, q Distribution) float64 {
+	return math.Sqrt(1 - BhattacharyyaCoefficient(p, q))
+}
+
+// --- }}}
@@ -0,0 +1,159 @@
+package prob
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+func TestEntropy(t *testing.T) {
+	for _, n := range []int{1, 2, 6, 10} {
+		outcomes := make([]set.Element, n)
+		for i := range outcomes {
+			outcomes[i] = i
+		}
+
+		d := NewUniformDiscrete(set.With(outcomes))
+
+		if h := Entropy(d); !equiv(h, math.Log2(float64(n))) {
+			t.Errorf("Entropy(uniform over %d) = %f, want %f", n, h, math.Log2(float64(n)))
+		}
+
+		if h := EntropyBase(d, math.E); !equiv(h, math.Log(float64(n))) {
+			t.Errorf("EntropyBase(uniform over %d, e) = %f, want %f", n, h, math.Log(float64(n)))
+		}
+	}
+
+	// a zero mass contributes nothing, rather than NaN
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+	d.AddOutcome(1, Certain)
+
+	if h := Entropy(d); h != 0 {
+		t.Errorf("Entropy(degenerate) = %f, want 0", h)
+	}
+}
+
+func TestKLDivergence(t *testing.T) {
+	p := NewCategorical(Outcomes{1, 2, 3}, []float64{0.5, 0.25, 0.25})
+	q := NewCategorical(Outcomes{1, 2, 3}, []float64{1, 1, 2})
+
+	if kl := KLDivergence(p, p); kl != 0 {
+		t.Errorf("D(p || p) = %f, want 0", kl)
+	}
+
+	// 0.5 log2(0.5/0.25) + 0.25 log2(0.25/0.25) + 0.25 log2(0.25/0.5)
+	if kl := KLDivergence(p, q); !equiv(kl, 0.25) {
+		t.Errorf("D(p || q) = %f, want 0.25", kl)
+	}
+
+	if kl := KLDivergence(q, p); kl < 0 {
+		t.Errorf("D(q || p) = %f, want non-negative", kl)
+	}
+
+	if h := CrossEntropy(p, q); !equiv(h, Entropy(p)+KLDivergence(p, q)) {
+		t.Errorf("H(p, q) = %f, want H(p) + D(p || q) = %f", h, Entropy(p)+KLDivergence(p, q))
+	}
+
+	r := NewCategorical(Outcomes{1, 2, 3}, []float64{1, 1, 0})
+	if kl := KLDivergence(p, r); !math.IsInf(kl, 1) {
+		t.Errorf("D(p || r) = %f, want +Inf where r has no mass", kl)
+	}
+}
+
+func TestTotalVariationDistance(t *testing.T) {
+	d := die()
+
+	if tv := TotalVariationDistance(d, d); tv != 0 {
+		t.Errorf("δ(d, d) = %f, want 0", tv)
+	}
+
+	domain := set.WithElements(1, 2)
+	p, q := NewDegenerate(1, domain), NewDegenerate(2, domain)
+
+	if tv := TotalVariationDistance(p, q); !equiv(tv, 1) {
+		t.Errorf("δ(p, q) = %f, want 1 for disjoint supports", tv)
+	}
+}
+
+func TestHellingerDistance(t *testing.T) {
+	p := NewCategorical(Outcomes{1, 2, 3}, []float64{0.5, 0.25, 0.25})
+	q := NewCategorical(Outcomes{1, 2, 3}, []float64{1, 1, 2})
+
+	if h := HellingerDistance(p, p); h != 0 {
+		t.Errorf("H(p, p) = %f, want 0", h)
+	}
+
+	if h := HellingerDistance(p, q); h <= 0 || h > 1 {
+		t.Errorf("H(p, q) = %f, want on (0, 1]", h)
+	}
+
+	if bc := BhattacharyyaCoefficient(p, q); bc < 0 || bc > 1 {
+		t.Errorf("BC(p, q) = %f, want on [0, 1]", bc)
+	}
+
+	domain := set.WithElements(1, 2)
+	r, s := NewDegenerate(1, domain), NewDegenerate(2, domain)
+
+	if bc := BhattacharyyaCoefficient(r, s); bc != 0 {
+		t.Errorf("BC(r, s) = %f, want 0 for disjoint supports", bc)
+	}
+
+	if h := HellingerDistance(r, s); !equiv(h, 1) {
+		t.Errorf("H(r, s) = %f, want 1 for disjoint supports", h)
+	}
+}
+
+func TestJointEntropy(t *testing.T) {
+	d := die()
+	parity := func(o Outcome) float64 { return float64(o.(int) % 2) }
+
+	if h, want := JointEntropy(d, parity, value), variableEntropy(d, parity)+ConditionalEntropy(d, parity, value); !equiv(h, want) {
+		t.Errorf("H(X, Y) = %f, want H(X) + H(Y | X) = %f", h, want)
+	}
+
+	two := Product(d, d)
+	X := func(o Outcome) float64 { return value(first(o)) }
+	Y := func(o Outcome) float64 { return value(second(o)) }
+
+	if h, want := JointEntropy(two, X, Y), variableEntropy(two, X)+variableEntropy(two, Y); !equiv(h, want) {
+		t.Errorf("H(X, Y) = %f, want H(X) + H(Y) = %f for independent X and Y", h, want)
+	}
+
+	if h := JointEntropy(two, X, Y); !equiv(h, 2*math.Log2(6)) {
+		t.Errorf("H(X, Y) = %f, want %f", h, 2*math.Log2(6))
+	}
+}
+
+func TestRenyiEntropy(t *testing.T) {
+	for _, n := range []int{2, 6, 10} {
+		outcomes := make([]set.Element, n)
+		for i := range outcomes {
+			outcomes[i] = i
+		}
+
+		d := NewUniformDiscrete(set.With(outcomes))
+
+		if h := RenyiEntropy(d, 2); !equiv(h, math.Log2(float64(n))) {
+			t.Errorf("H_2(uniform over %d) = %f, want %f", n, h, math.Log2(float64(n)))
+		}
+	}
+
+	p := NewCategorical(Outcomes{1, 2, 3}, []float64{0.5, 0.25, 0.25})
+	q := NewCategorical(Outcomes{1, 2, 3}, []float64{1, 1, 2})
+
+	if h := RenyiEntropy(p, 1); h != Entropy(p) {
+		t.Errorf("H_1(p) = %f, want H(p) = %f", h, Entropy(p))
+	}
+
+	// the limit as alpha → 1, from either side
+	for _, alpha := range []float64{1 - 1e-4, 1 + 1e-4} {
+		if h := RenyiEntropy(p, alpha); math.Abs(h-Entropy(p)) > 1e-3 {
+			t.Errorf("H_%f(p) = %f, want near H(p) = %f", alpha, h, Entropy(p))
+		}
+
+		if d := RenyiDivergence(p, q, alpha); math.Abs(d-KLDivergence(p, q)) > 1e-3 {
+			t.Errorf("D_%f(p || q) = %f, want near D(p || q) = %f", alpha, d, KLDivergence(p, q))
+		}
+	}
+}
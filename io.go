@@ -0,0 +1,134 @@
+package prob
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nlandolfi/set"
+)
+
+// --- Samples {{{
+
+// ReadSamples reads line-delimited samples from r, parsing each
+// non-blank line into an Outcome with parse.
+//
+// On the first failure to parse a line, ReadSamples returns the
+// outcomes read so far, along with an error wrapping the failure.
+func ReadSamples(r io.Reader, parse func(string) (Outcome, error)) (Outcomes, error) {
+	var samples Outcomes
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		token := strings.TrimSpace(scanner.Text())
+		if token == "" {
+			continue
+		}
+
+		o, err := parse(token)
+		if err != nil {
+			return samples, fmt.Errorf("prob: parsing sample on line %d: %w", line, err)
+		}
+
+		samples = append(samples, o)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return samples, fmt.Errorf("prob: reading samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// --- }}}
+
+// --- JSON {{{
+
+// jsonDistribution is the JSON representation of a distribution
+type jsonDistribution struct {
+	Domain  Outcomes   `json:"domain"`
+	Support []jsonMass `json:"support"`
+}
+
+// jsonMass is the JSON representation of an outcome's probability
+type jsonMass struct {
+	Outcome     Outcome     `json:"outcome"`
+	Probability Probability `json:"probability"`
+}
+
+// MarshalJSON encodes the distribution as its domain, and its support
+// as an array of {outcome, probability} pairs. The domain must be
+// enumerable.
+//
+// Note: outcomes are encoded as JSON values, and decoded as the
+// corresponding generic Go values, so only strings, booleans and
+// float64s round-trip exactly; e.g., an int outcome is decoded as a
+// float64. Outcomes which are not JSON scalars can not be decoded.
+func (d *distribution) MarshalJSON() ([]byte, error) {
+	domain, ok := d.domain.(set.Interface)
+	if !ok {
+		return nil, errors.New("prob: can not marshal distribution over a domain which is not enumerable")
+	}
+
+	j := jsonDistribution{
+		Domain:  sorted(domain.Elements()),
+		Support: make([]jsonMass, 0, len(d.support)),
+	}
+
+	for _, o := range sorted(d.outcomes.Elements()) {
+		j.Support = append(j.Support, jsonMass{o, d.support[o]})
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a distribution encoded by MarshalJSON,
+// replacing the domain and support of d.
+func (d *distribution) UnmarshalJSON(b []byte) error {
+	var j jsonDistribution
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	domain := set.New()
+	for _, o := range j.Domain {
+		if !scalar(o) {
+			return fmt.Errorf("prob: can not decode outcome %v", o)
+		}
+
+		domain.Add(o)
+	}
+
+	masses := make(map[Outcome]Probability)
+	for _, m := range j.Support {
+		if !domain.Contains(m.Outcome) {
+			return fmt.Errorf("prob: outcome %v not in domain", m.Outcome)
+		}
+
+		if !m.Probability.Valid() {
+			return fmt.Errorf("prob: invalid probability %v of outcome %v", m.Probability, m.Outcome)
+		}
+
+		masses[m.Outcome] = m.Probability
+	}
+
+	*d = *newDistribution(domain, masses)
+
+	return nil
+}
+
+// scalar determines whether a decoded JSON value is a scalar, and
+// so may be an outcome
+func scalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, float64, nil:
+		return true
+	}
+
+	return false
+}
+
+// --- }}}
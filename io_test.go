@@ -0,0 +1,76 @@
+package prob
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseInt parses an integer outcome
+func parseInt(s string) (Outcome, error) {
+	return strconv.Atoi(s)
+}
+
+func TestReadSamples(t *testing.T) {
+	samples, err := ReadSamples(strings.NewReader("1\n2\n\n3\n 2 \n"), parseInt)
+	if err != nil {
+		t.Fatalf("ReadSamples error: %v", err)
+	}
+
+	want := Outcomes{1, 2, 3, 2}
+	if len(samples) != len(want) {
+		t.Fatalf("ReadSamples = %v, want %v", samples, want)
+	}
+
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("samples[%d] = %v, want %v", i, samples[i], want[i])
+		}
+	}
+}
+
+func TestReadSamplesParseError(t *testing.T) {
+	samples, err := ReadSamples(strings.NewReader("1\n2\nthree\n4\n"), parseInt)
+
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("ReadSamples error = %v, want a wrapped *strconv.NumError", err)
+	}
+
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ReadSamples error = %q, want it to name line 3", err)
+	}
+
+	if len(samples) != 2 {
+		t.Errorf("ReadSamples returned %d samples, want the 2 before the error", len(samples))
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	b, err := json.Marshal(die())
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	d := new(distribution)
+	if err := json.Unmarshal(b, d); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	if c := Cardinality(d); c != 6 {
+		t.Errorf("Cardinality = %d, want 6", c)
+	}
+
+	// int outcomes are decoded as float64s
+	for face := 1; face <= 6; face++ {
+		if p := d.ProbabilityOf(float64(face)); !equiv(float64(p), 1.0/6) {
+			t.Errorf("P(%d) = %f, want %f", face, p, 1.0/6)
+		}
+	}
+
+	if !FullySupported(d) {
+		t.Errorf("decoded distribution is not fully supported")
+	}
+}
@@ -0,0 +1,92 @@
+package prob
+
+import "github.com/nlandolfi/set"
+
+// --- Pairs {{{
+
+// A Pair is an outcome of two experiments, considered jointly. A
+// joint distribution over two experiments has Pairs as outcomes.
+type Pair struct {
+	First, Second Outcome
+}
+
+// first projects a Pair outcome to its first component
+func first(o Outcome) Outcome {
+	p, ok := o.(Pair)
+	assert(ok, "outcome is not a Pair")
+	return p.First
+}
+
+// second projects a Pair outcome to its second component
+func second(o Outcome) Outcome {
+	p, ok := o.(Pair)
+	assert(ok, "outcome is not a Pair")
+	return p.Second
+}
+
+// --- }}}
+
+// --- Product {{{
+
+// Product constructs the joint distribution of two independent
+// experiments, distributed according to p and q. Its outcomes are
+// Pairs, and its domain is the Cartesian product of the domains of
+// p and q, which must be enumerable.
+//
+// Recall: P(a, b) = P(a)P(b), for independent experiments
+func Product(p, q DiscreteDistribution) DiscreteDistribution {
+	pd, ok := p.Domain().(set.Interface)
+	assert(ok, "domain of first distribution is not enumerable")
+
+	qd, ok := q.Domain().(set.Interface)
+	assert(ok, "domain of second distribution is not enumerable")
+
+	domain := set.New()
+	for _, a := range pd.Elements() {
+		for _, b := range qd.Elements() {
+			domain.Add(Pair{a, b})
+		}
+	}
+
+	masses := make(map[Outcome]Probability)
+	for _, a := range p.Outcomes().Elements() {
+		for _, b := range q.Outcomes().Elements() {
+			masses[Pair{a, b}] = p.ProbabilityOf(a) * q.ProbabilityOf(b)
+		}
+	}
+
+	return newDistribution(domain, masses)
+}
+
+// --- }}}
+
+// --- Marginals {{{
+
+// Marginal computes the marginal distribution of a joint distribution
+// d, over the space onto which project maps its outcomes. The masses
+// of all outcomes sharing a projected value are summed.
+//
+// For example, projecting Pair outcomes onto their first component
+// recovers the distribution of the first experiment (see FirstMarginal).
+// Marginal is Transform, viewed as a projection.
+func Marginal(d DiscreteDistribution, project func(Outcome) Outcome) DiscreteDistribution {
+	return Transform(d, project)
+}
+
+// FirstMarginal computes the marginal distribution of the first
+// component of a joint distribution over Pairs.
+//
+// Recall: P(X = a) = Σ_b P(X = a, Y = b)
+func FirstMarginal(joint DiscreteDistribution) DiscreteDistribution {
+	return Marginal(joint, first)
+}
+
+// SecondMarginal computes the marginal distribution of the second
+// component of a joint distribution over Pairs.
+//
+// Recall: P(Y = b) = Σ_a P(X = a, Y = b)
+func SecondMarginal(joint DiscreteDistribution) DiscreteDistribution {
+	return Marginal(joint, second)
+}
+
+// --- }}}
@@ -0,0 +1,74 @@
+package prob
+
+import (
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+func TestFirstAndSecondMarginal(t *testing.T) {
+	masses := map[Outcome]Probability{
+		Pair{"H", 1}: 0.1, Pair{"H", 2}: 0.2,
+		Pair{"T", 1}: 0.3, Pair{"T", 2}: 0.4,
+	}
+
+	domain := set.New()
+	for o := range masses {
+		domain.Add(o)
+	}
+
+	joint := newDistribution(domain, masses)
+
+	for o, want := range map[Outcome]float64{"H": 0.3, "T": 0.7} {
+		if p := FirstMarginal(joint).ProbabilityOf(o); !equiv(float64(p), want) {
+			t.Errorf("FirstMarginal P(%v) = %f, want %f", o, p, want)
+		}
+	}
+
+	for o, want := range map[Outcome]float64{1: 0.4, 2: 0.6} {
+		if p := SecondMarginal(joint).ProbabilityOf(o); !equiv(float64(p), want) {
+			t.Errorf("SecondMarginal P(%v) = %f, want %f", o, p, want)
+		}
+	}
+}
+
+func TestFirstMarginalNotPair(t *testing.T) {
+	assertPanics(t, func() {
+		FirstMarginal(NewUniformDiscrete(set.WithElements(1, 2)))
+	})
+}
+
+func TestProduct(t *testing.T) {
+	coin := NewUniformDiscrete(set.WithElements("H", "T"))
+	joint := Product(coin, coin)
+
+	if c := Cardinality(joint); c != 4 {
+		t.Errorf("Cardinality(joint) = %d, want 4", c)
+	}
+
+	for _, a := range []string{"H", "T"} {
+		for _, b := range []string{"H", "T"} {
+			if p := joint.ProbabilityOf(Pair{a, b}); !equiv(float64(p), 0.25) {
+				t.Errorf("P(%s, %s) = %f, want 0.25", a, b, p)
+			}
+		}
+	}
+
+	if !sameMasses(FirstMarginal(joint), coin) || !sameMasses(SecondMarginal(joint), coin) {
+		t.Errorf("marginals of the joint do not recover the coins")
+	}
+}
+
+func TestMarginal(t *testing.T) {
+	m := Marginal(Product(die(), die()), first)
+
+	for _, o := range die().Outcomes().Elements() {
+		if p := m.ProbabilityOf(o); !equiv(float64(p), 1.0/6) {
+			t.Errorf("P(%v) = %f, want %f", o, p, 1.0/6)
+		}
+	}
+
+	if !FullySupported(m) {
+		t.Errorf("marginal is not fully supported")
+	}
+}
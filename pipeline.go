@@ -0,0 +1,44 @@
+package prob
+
+// --- Pipeline {{{
+
+// A Pipeline builds a DiscreteDistribution through a chain of
+// transformations. Each step returns a new Pipeline, leaving the
+// original unchanged, so a Pipeline can be branched.
+//
+//	d := NewPipeline(die).
+//		Map(double).
+//		Condition(evens).
+//		Build()
+type Pipeline struct {
+	d DiscreteDistribution
+}
+
+// NewPipeline starts a Pipeline from the distribution d
+func NewPipeline(d DiscreteDistribution) *Pipeline {
+	return &Pipeline{d: d}
+}
+
+// Map transforms the outcomes of the pipeline by f. See Transform.
+func (p *Pipeline) Map(f func(Outcome) Outcome) *Pipeline {
+	return &Pipeline{d: Transform(p.d, f)}
+}
+
+// Mix composes the pipeline's distribution with other, taking the
+// pipeline's outcomes with probability alpha. See Compose.
+func (p *Pipeline) Mix(other DiscreteDistribution, alpha Probability) *Pipeline {
+	return &Pipeline{d: Compose(p.d, other, alpha)}
+}
+
+// Condition conditions the pipeline's distribution on the event A.
+// See Conditional.
+func (p *Pipeline) Condition(A Event) *Pipeline {
+	return &Pipeline{d: Conditional(p.d, A)}
+}
+
+// Build finalizes the pipeline, returning the distribution built
+func (p *Pipeline) Build() DiscreteDistribution {
+	return p.d
+}
+
+// --- }}}
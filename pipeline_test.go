@@ -0,0 +1,46 @@
+package prob
+
+import (
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+func TestPipeline(t *testing.T) {
+	double := func(o Outcome) Outcome {
+		return 2 * o.(int)
+	}
+	high := set.WithElements(8, 10, 12)
+
+	got := NewPipeline(die()).Map(double).Condition(high).Build()
+	want := Conditional(Transform(die(), double), high)
+
+	if !sameMasses(got, want) {
+		t.Errorf("Map then Condition differs from Conditional(Transform(...))")
+	}
+
+	loaded := newDistribution(die().Outcomes(), map[Outcome]Probability{
+		1: 0.1, 2: 0.1, 3: 0.1, 4: 0.1, 5: 0.1, 6: 0.5,
+	})
+
+	got = NewPipeline(die()).Mix(loaded, 0.25).Map(double).Build()
+	want = Transform(Compose(die(), loaded, 0.25), double)
+
+	if !sameMasses(got, want) {
+		t.Errorf("Mix then Map differs from Transform(Compose(...))")
+	}
+}
+
+// sameMasses reports whether p and q assign equivalent probability
+// to each outcome of either distribution
+func sameMasses(p, q DiscreteDistribution) bool {
+	for _, d := range []DiscreteDistribution{p, q} {
+		for _, o := range d.Outcomes().Elements() {
+			if !equiv(float64(p.ProbabilityOf(o)), float64(q.ProbabilityOf(o))) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
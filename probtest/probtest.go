@@ -0,0 +1,67 @@
+// Package probtest provides helpers for testing code built on the
+// prob package.
+package probtest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/nlandolfi/prob"
+)
+
+// AssertSamples simulates n experiments with the distribution d, and
+// fails the test if the empirical frequency of any outcome deviates
+// from its probability by more than tol.
+func AssertSamples(t testing.TB, d prob.DiscreteDistribution, n int, tol float64) {
+	t.Helper()
+
+	counts := make(map[prob.Outcome]int)
+	for _, o := range prob.Sample(d, n) {
+		counts[o]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		freq, p := float64(counts[o])/float64(n), float64(d.ProbabilityOf(o))
+
+		if math.Abs(freq-p) > tol {
+			t.Errorf("outcome %v: empirical frequency %f, probability %f (tolerance %f)", o, freq, p, tol)
+		}
+	}
+}
+
+// AssertDistributionEqual fails the test if the probability of any
+// outcome under got differs from its probability under want by more
+// than tol, reporting every such outcome.
+func AssertDistributionEqual(t testing.TB, got, want prob.Distribution, tol float64) {
+	t.Helper()
+
+	if lines := diff(got, want, tol); len(lines) > 0 {
+		t.Errorf("distributions differ:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+// diff describes each outcome of got or want whose probabilities
+// differ by more than tol
+func diff(got, want prob.Distribution, tol float64) []string {
+	var lines []string
+
+	seen := make(map[prob.Outcome]bool)
+	for _, o := range append(got.Outcomes().Elements(), want.Outcomes().Elements()...) {
+		if seen[o] {
+			continue
+		}
+		seen[o] = true
+
+		// outcomes outside a domain are impossible under it
+		g, _ := prob.ProbabilityOfSafe(got, o)
+		w, _ := prob.ProbabilityOfSafe(want, o)
+
+		if math.Abs(float64(g-w)) > tol {
+			lines = append(lines, fmt.Sprintf("\toutcome %v: got %f, want %f", o, g, w))
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,68 @@
+package probtest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nlandolfi/prob"
+	"github.com/nlandolfi/prob/probtest"
+	"github.com/nlandolfi/set"
+)
+
+// recorder is a testing.TB which records, rather than reports, errors
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// die constructs the distribution of a fair six-sided die
+func die() prob.DiscreteDistribution {
+	return prob.NewUniformDiscrete(set.WithElements(1, 2, 3, 4, 5, 6))
+}
+
+func TestAssertSamples(t *testing.T) {
+	probtest.AssertSamples(t, die(), 100000, 0.01)
+
+	// no frequency can be within a negative tolerance
+	r := &recorder{TB: t}
+	probtest.AssertSamples(r, die(), 1000, -1)
+
+	if len(r.errors) != 6 {
+		t.Errorf("AssertSamples reported %d errors, want one per outcome: %v", len(r.errors), r.errors)
+	}
+}
+
+func TestAssertDistributionEqual(t *testing.T) {
+	probtest.AssertDistributionEqual(t, die(), die(), 1e-9)
+
+	loaded := prob.NewCategorical(prob.Outcomes{1, 2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 2, 4})
+
+	r := &recorder{TB: t}
+	probtest.AssertDistributionEqual(r, loaded, die(), 0.01)
+
+	if len(r.errors) != 1 {
+		t.Fatalf("AssertDistributionEqual reported %d errors, want 1: %v", len(r.errors), r.errors)
+	}
+
+	msg := r.errors[0]
+	for _, line := range []string{
+		"\toutcome 1: got 0.100000, want 0.166667",
+		"\toutcome 5: got 0.200000, want 0.166667",
+		"\toutcome 6: got 0.400000, want 0.166667",
+	} {
+		if !strings.Contains(msg, line+"\n") && !strings.HasSuffix(msg, line) {
+			t.Errorf("failure message %q is missing line %q", msg, line)
+		}
+	}
+
+	if n := strings.Count(msg, "outcome"); n != 6 {
+		t.Errorf("failure message names %d outcomes, want all 6, as each differs", n)
+	}
+}
@@ -0,0 +1,423 @@
+package prob
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// --- Cumulative Mass {{{
+
+// Cumulative computes the cumulative mass of the distribution d. It
+// returns the outcomes of d, and a parallel slice whose ith entry is
+// the total probability of the first i+1 outcomes.
+//
+// The outcomes are in a stable order, as for SimulateWith.
+//
+// Precomputing the cumulative mass once allows repeated simulation
+// by binary search, rather than a linear scan per experiment.
+func Cumulative(d DiscreteDistribution) (Outcomes, []Probability) {
+	outcomes := sorted(d.Outcomes().Elements())
+	cum := make([]Probability, len(outcomes))
+
+	p := Impossible
+	for i, o := range outcomes {
+		p += d.ProbabilityOf(o)
+		cum[i] = p
+	}
+
+	return outcomes, cum
+}
+
+// SimulateIndex simulates an experiment over precomputed cumulative
+// masses, cum, choosing outcomes[i] with probability proportional to
+// cum[i] - cum[i-1]. The outcomes are typically indices into some
+// parallel slices, which avoids boxing the outcomes in hot loops.
+//
+//	os, cum := Cumulative(d)
+//	indices := []int{0, 1, ..., len(os)-1}
+//	os[SimulateIndex(indices, cum, r)] => o w.p. P(o)
+func SimulateIndex(outcomes []int, cum []Probability, r *rand.Rand) int {
+	assert(len(outcomes) == len(cum), "outcomes and cumulative masses differ in length")
+	assert(len(cum) > 0, "no outcomes to simulate")
+
+	return outcomes[search(cum, r.Float64())]
+}
+
+// Sample simulates n independent experiments with the distribution
+// defined by the DiscreteDistribution, returning their outcomes.
+//
+// The cumulative mass is computed once, so each experiment costs a
+// binary search rather than the linear scan of Simulate. As for
+// Simulate, the draws are scaled by the total support of d, which
+// must be positive.
+func Sample(d DiscreteDistribution, n int) Outcomes {
+	outcomes, cum := Cumulative(d)
+	assert(len(cum) > 0 && cum[len(cum)-1] > Impossible, "discrete distribution has no support")
+
+	samples := make(Outcomes, n)
+
+	for i := range samples {
+		samples[i] = outcomes[search(cum, rand.Float64())]
+	}
+
+	return samples
+}
+
+// ParallelSample simulates n independent experiments with the
+// distribution defined by the DiscreteDistribution, as Sample does,
+// but splits the work across GOMAXPROCS goroutines. Each goroutine
+// draws from its own generator, so they do not contend for the lock
+// of the global source.
+//
+// The outcomes are concatenated shard by shard, so their order is
+// deterministic given the seeds. The seed of each shard is drawn from
+// the global source.
+func ParallelSample(d DiscreteDistribution, n int) Outcomes {
+	outcomes, cum := Cumulative(d)
+	assert(len(cum) > 0 && cum[len(cum)-1] > Impossible, "discrete distribution has no support")
+
+	samples := make(Outcomes, n)
+
+	shards := runtime.GOMAXPROCS(0)
+	size := (n + shards - 1) / shards
+
+	var wg sync.WaitGroup
+
+	for lo := 0; lo < n; lo += size {
+		hi := lo + size
+		if hi > n {
+			hi = n
+		}
+
+		wg.Add(1)
+		go func(shard Outcomes, r *rand.Rand) {
+			defer wg.Done()
+
+			for i := range shard {
+				shard[i] = outcomes[search(cum, r.Float64())]
+			}
+		}(samples[lo:hi], rand.New(rand.NewSource(rand.Int63())))
+	}
+
+	wg.Wait()
+
+	return samples
+}
+
+// StratifiedSample draws n outcomes of the distribution d, drawing from
+// the generator r, by partitioning [0, 1) into n strata of equal width
+// and simulating one experiment per stratum. The frequency of each
+// outcome has lower variance than with the independent experiments of
+// Sample, which makes it suited to resampling, e.g., in particle
+// filters.
+//
+// The outcomes are returned in the order of Cumulative. As for Sample,
+// the strata are scaled by the total support of d, which must be
+// positive.
+func StratifiedSample(d DiscreteDistribution, n int, r *rand.Rand) Outcomes {
+	outcomes, cum := Cumulative(d)
+	assert(len(cum) > 0 && cum[len(cum)-1] > Impossible, "discrete distribution has no support")
+
+	samples := make(Outcomes, n)
+
+	for i := range samples {
+		samples[i] = outcomes[search(cum, (float64(i)+r.Float64())/float64(n))]
+	}
+
+	return samples
+}
+
+// SamplePMF simulates an experiment with the integer-valued distribution
+// given by its probability mass function, pmf, over the outcomes in
+// support, drawing from the generator r. The pmf need not be normalized
+// over support; outcomes are chosen proportional to their mass.
+func SamplePMF(pmf func(int) Probability, support []int, r *rand.Rand) int {
+	return support[search(pmfCumulative(pmf, support), r.Float64())]
+}
+
+// SamplePMFs simulates n independent experiments with the distribution
+// given by pmf over support, drawing from the generator r. See SamplePMF.
+//
+// The cumulative mass is computed once, so each experiment costs a
+// binary search.
+func SamplePMFs(pmf func(int) Probability, support []int, n int, r *rand.Rand) []int {
+	cum := pmfCumulative(pmf, support)
+	samples := make([]int, n)
+
+	for i := range samples {
+		samples[i] = support[search(cum, r.Float64())]
+	}
+
+	return samples
+}
+
+// pmfCumulative computes the cumulative mass of pmf over support, see
+// Cumulative
+func pmfCumulative(pmf func(int) Probability, support []int) []Probability {
+	assert(len(support) > 0, "no outcomes to simulate")
+
+	cum := make([]Probability, len(support))
+
+	p := Impossible
+	for i, k := range support {
+		p += pmf(k)
+		cum[i] = p
+	}
+
+	assert(p > Impossible, "pmf has no mass over support")
+
+	return cum
+}
+
+// search finds the index of the first cumulative mass exceeding
+// f ∈ [0, 1), scaled to the total mass of cum
+func search(cum []Probability, f float64) int {
+	target := Probability(f) * cum[len(cum)-1]
+
+	i := sort.Search(len(cum), func(i int) bool {
+		return target < cum[i]
+	})
+
+	if i == len(cum) {
+		return len(cum) - 1
+	}
+
+	return i
+}
+
+// --- }}}
+
+// --- Alias Method {{{
+
+// An AliasSampler simulates experiments with a discrete distribution
+// in constant time per experiment, using Vose's alias method. It is
+// preferable to Simulate when drawing repeatedly from a distribution
+// with many outcomes.
+type AliasSampler struct {
+	outcomes Outcomes
+	prob     []float64
+	alias    []int
+}
+
+// NewAliasSampler constructs an AliasSampler for the distribution d,
+// precomputing its alias tables in O(n) time, for n outcomes. As for
+// Simulate, the masses are scaled by the total support of d, which
+// must be positive.
+func NewAliasSampler(d DiscreteDistribution) *AliasSampler {
+	total := float64(Support(d))
+	assert(total > 0, "discrete distribution has no support")
+
+	outcomes := sorted(d.Outcomes().Elements())
+	n := len(outcomes)
+
+	a := &AliasSampler{
+		outcomes: outcomes,
+		prob:     make([]float64, n),
+		alias:    make([]int, n),
+	}
+
+	// scale the masses so that their mean is 1, and partition them
+	// into those below (small) and at or above (large) the mean
+	scaled := make([]float64, n)
+	var small, large []int
+
+	for i, o := range outcomes {
+		scaled[i] = float64(d.ProbabilityOf(o)) * float64(n) / total
+
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	// each small column is topped up to 1 by a large column
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small, large = small[:len(small)-1], large[:len(large)-1]
+
+		a.prob[s] = scaled[s]
+		a.alias[s] = l
+
+		scaled[l] += scaled[s] - 1
+
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+
+	// any remaining columns are full, up to floating point error
+	for _, i := range append(small, large...) {
+		a.prob[i] = 1
+	}
+
+	return a
+}
+
+// Draw simulates an experiment, drawing from the generator r
+func (a *AliasSampler) Draw(r *rand.Rand) Outcome {
+	i := r.Intn(len(a.outcomes))
+
+	if r.Float64() < a.prob[i] {
+		return a.outcomes[i]
+	}
+
+	return a.outcomes[a.alias[i]]
+}
+
+// --- }}}
+
+// --- Allocation {{{
+
+// AllocateProportional apportions total units among the outcomes
+// of the distribution d, proportional to their probabilities.
+//
+// The largest remainder (Hamilton) method is used: each outcome is
+// first allocated the floor of its quota, total*P(o), then the units
+// left over are given, one each, to the outcomes with the largest
+// fractional remainders. The allocations always sum to total. Ties
+// are broken by the order of the outcomes, so the allocations are
+// deterministic when the outcomes are numbers or strings.
+func AllocateProportional(d DiscreteDistribution, total int) map[Outcome]int {
+	if checks && !FullySupported(d) {
+		panic("discrete distribution not fully supported")
+	}
+	assert(total >= 0, "total must be non-negative")
+
+	type quota struct {
+		outcome   Outcome
+		remainder float64
+	}
+
+	allocation := make(map[Outcome]int)
+	quotas := make([]quota, 0, Cardinality(d))
+	allocated := 0
+
+	// normalize by the support, so the quotas sum to total, and
+	// consider the outcomes in a stable order, so ties in the
+	// remainders are always broken the same way
+	support := float64(Support(d))
+
+	for _, o := range sorted(d.Outcomes().Elements()) {
+		q := float64(total) * float64(d.ProbabilityOf(o)) / support
+		whole := math.Floor(q)
+
+		allocation[o] = int(whole)
+		allocated += int(whole)
+		quotas = append(quotas, quota{o, q - whole})
+	}
+
+	sort.SliceStable(quotas, func(i, j int) bool {
+		return quotas[i].remainder > quotas[j].remainder
+	})
+
+	for i := 0; allocated < total; i = (i + 1) % len(quotas) {
+		allocation[quotas[i].outcome]++
+		allocated++
+	}
+
+	return allocation
+}
+
+// --- }}}
+
+// --- Shuffling {{{
+
+// ShuffleOutcomes returns a uniformly random permutation of the
+// outcomes os, drawing from the generator r, by the Fisher-Yates
+// shuffle. The outcomes os are not modified, and the same seed
+// yields the same permutation.
+func ShuffleOutcomes(os Outcomes, r *rand.Rand) Outcomes {
+	shuffled := make(Outcomes, len(os))
+	copy(shuffled, os)
+
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+
+	return shuffled
+}
+
+// --- }}}
+
+// --- Reservoir Sampling {{{
+
+// ReservoirSample draws a uniformly random sample of k outcomes, without
+// replacement, from a stream of outcomes of unknown length, drawing
+// from the generator r. It consumes the stream until it is closed,
+// using Algorithm R, so each outcome is kept with probability k/n,
+// for a stream of n outcomes.
+//
+// If the stream has fewer than k outcomes, they are all returned.
+func ReservoirSample(stream <-chan Outcome, k int, r *rand.Rand) Outcomes {
+	assert(k >= 0, "sample size must be non-negative")
+
+	reservoir := make(Outcomes, 0, k)
+	n := 0
+
+	for o := range stream {
+		n++
+
+		if len(reservoir) < k {
+			reservoir = append(reservoir, o)
+			continue
+		}
+
+		if j := r.Intn(n); j < k {
+			reservoir[j] = o
+		}
+	}
+
+	return reservoir
+}
+
+// --- }}}
+
+// --- Point Processes {{{
+
+// Thin independently keeps each event time of a point process with
+// probability p, drawing from the generator r. Thinning a Poisson
+// process with rate λ yields a Poisson process with rate pλ.
+func Thin(times []float64, p Probability, r *rand.Rand) []float64 {
+	assert(p.Valid(), "invalid probability")
+
+	var kept []float64
+
+	for _, t := range times {
+		if Probability(r.Float64()) < p {
+			kept = append(kept, t)
+		}
+	}
+
+	return kept
+}
+
+// --- }}}
+
+// --- Monte Carlo {{{
+
+// MonteCarloExpectation estimates the expected value of f(X), where X
+// is drawn by sampler, by averaging f over n independent samples drawn
+// from the generator r. It is useful for distributions whose
+// expectations can not be computed analytically.
+//
+// Recall: E[f(X)] ≈ (1/n) Σ_i f(x_i), with error O(1/√n)
+func MonteCarloExpectation(sampler func(*rand.Rand) float64, f func(float64) float64, n int, r *rand.Rand) float64 {
+	assert(n > 0, "number of samples must be positive")
+
+	sum := 0.0
+
+	for i := 0; i < n; i++ {
+		sum += f(sampler(r))
+	}
+
+	return sum / float64(n)
+}
+
+// --- }}}
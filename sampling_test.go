@@ -0,0 +1,495 @@
+package prob
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/nlandolfi/set"
+)
+
+func TestAllocateProportional(t *testing.T) {
+	d := newDistribution(set.WithElements("a", "b", "c"), map[Outcome]Probability{"a": 0.5, "b": 0.3, "c": 0.2})
+
+	for _, total := range []int{0, 1, 7, 10, 99, 1000} {
+		allocation := AllocateProportional(d, total)
+
+		sum := 0
+		for _, o := range d.Outcomes().Elements() {
+			n := allocation[o]
+			sum += n
+
+			if quota := float64(total) * float64(d.ProbabilityOf(o)); math.Abs(float64(n)-quota) >= 1 {
+				t.Errorf("total %d: allocation[%v] = %d, want within 1 of %f", total, o, n, quota)
+			}
+		}
+
+		if sum != total {
+			t.Errorf("allocations sum to %d, want %d", sum, total)
+		}
+	}
+}
+
+func TestAllocateProportionalTies(t *testing.T) {
+	d := die()
+	want := AllocateProportional(d, 10)
+
+	for i := 0; i < 20; i++ {
+		allocation := AllocateProportional(die(), 10)
+
+		for o, n := range want {
+			if allocation[o] != n {
+				t.Fatalf("allocation[%v] = %d, want %d, as in a previous run", o, allocation[o], n)
+			}
+		}
+	}
+}
+
+func TestAllocateProportionalOverSupported(t *testing.T) {
+	// masses sum to 1 + 3e-6, which is fully supported within epsilon
+	masses := map[Outcome]Probability{1: 0.333334, 2: 0.333334, 3: 0.333335}
+	d := newDistribution(set.WithElements(1, 2, 3), masses)
+
+	allocation := AllocateProportional(d, 1000000)
+
+	sum := 0
+	for _, n := range allocation {
+		sum += n
+	}
+
+	if sum != 1000000 {
+		t.Errorf("allocations sum to %d, want %d", sum, 1000000)
+	}
+}
+
+// indices constructs the slice [0, 1, ..., n-1]
+func indices(n int) []int {
+	is := make([]int, n)
+	for i := range is {
+		is[i] = i
+	}
+
+	return is
+}
+
+func TestSimulateIndex(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2, 3, 4), map[Outcome]Probability{1: 0.1, 2: 0.2, 3: 0.3, 4: 0.4})
+	outcomes, cum := Cumulative(d)
+	is := indices(len(outcomes))
+	r := rand.New(rand.NewSource(1))
+
+	const n = 100000
+	counts := make([]int, len(outcomes))
+
+	for i := 0; i < n; i++ {
+		counts[SimulateIndex(is, cum, r)]++
+	}
+
+	for i, o := range outcomes {
+		freq, p := float64(counts[i])/n, float64(d.ProbabilityOf(o))
+		if math.Abs(freq-p) > 0.01 {
+			t.Errorf("frequency of index %d (outcome %v) = %f, want %f", i, o, freq, p)
+		}
+	}
+}
+
+// large constructs a distribution over n outcomes, with masses
+// proportional to 1, 2, ..., n
+func large(n int) DiscreteDistribution {
+	domain := set.New()
+	masses := make(map[Outcome]Probability)
+	total := float64(n*(n+1)) / 2
+
+	for i := 0; i < n; i++ {
+		domain.Add(i)
+		masses[i] = Probability(float64(i+1) / total)
+	}
+
+	return newDistribution(domain, masses)
+}
+
+func BenchmarkSimulate(b *testing.B) {
+	d := large(1000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Simulate(d)
+	}
+}
+
+func BenchmarkSimulateIndex(b *testing.B) {
+	d := large(1000)
+	r := rand.New(rand.NewSource(1))
+	outcomes, cum := Cumulative(d)
+	is := indices(len(outcomes))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		SimulateIndex(is, cum, r)
+	}
+}
+
+func TestSample(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2, 3, 4), map[Outcome]Probability{1: 0.1, 2: 0.2, 3: 0.3, 4: 0.4})
+
+	const n = 100000
+	samples := Sample(d, n)
+
+	if len(samples) != n {
+		t.Fatalf("len(Sample(d, %d)) = %d", n, len(samples))
+	}
+
+	counts := make(map[Outcome]int)
+	for _, o := range samples {
+		counts[o]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		freq, p := float64(counts[o])/n, float64(d.ProbabilityOf(o))
+		if math.Abs(freq-p) > 0.01 {
+			t.Errorf("frequency of %v = %f, want %f", o, freq, p)
+		}
+	}
+}
+
+func TestSampleUnderSupported(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2), map[Outcome]Probability{1: 0.49, 2: 0.49})
+
+	const n = 100000
+	counts := make(map[Outcome]int)
+	for _, o := range Sample(d, n) {
+		counts[o]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		if freq := float64(counts[o]) / n; math.Abs(freq-0.5) > 0.01 {
+			t.Errorf("frequency of %v = %f, want 0.5", o, freq)
+		}
+	}
+}
+
+func TestSampleNoSupport(t *testing.T) {
+	d := NewDiscreteDistribution(set.WithElements(1, 2))
+
+	assertPanics(t, func() {
+		Sample(d, 1)
+	})
+}
+
+func BenchmarkSample(b *testing.B) {
+	d := die()
+
+	for i := 0; i < b.N; i++ {
+		Sample(d, 100000)
+	}
+}
+
+func BenchmarkSimulateLoop(b *testing.B) {
+	d := die()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100000; j++ {
+			Simulate(d)
+		}
+	}
+}
+
+func TestAliasSampler(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2, 3, 4, 5), map[Outcome]Probability{1: 0.05, 2: 0.1, 3: 0.15, 4: 0.3, 5: 0.4})
+	a := NewAliasSampler(d)
+	r := rand.New(rand.NewSource(1))
+
+	const n = 1000000
+	counts := make(map[Outcome]int)
+
+	for i := 0; i < n; i++ {
+		counts[a.Draw(r)]++
+	}
+
+	// the standard error of each frequency is at most 0.0005
+	for _, o := range d.Outcomes().Elements() {
+		freq, p := float64(counts[o])/n, float64(d.ProbabilityOf(o))
+		if math.Abs(freq-p) > 0.003 {
+			t.Errorf("frequency of %v = %f, want %f", o, freq, p)
+		}
+	}
+}
+
+func TestAliasSamplerLarge(t *testing.T) {
+	d := large(5000)
+	a := NewAliasSampler(d)
+	r := rand.New(rand.NewSource(1))
+
+	// outcome i has mass proportional to i+1, so the mean is (2n+1)/3 - 1
+	const n = 200000
+	sum := 0.0
+
+	for i := 0; i < n; i++ {
+		sum += value(a.Draw(r))
+	}
+
+	if mean, want := sum/n, Expectation(d, value); math.Abs(mean-want) > 20 {
+		t.Errorf("mean of draws = %f, want %f", mean, want)
+	}
+}
+
+func TestAliasSamplerUnderSupported(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2), map[Outcome]Probability{1: 0.49, 2: 0.49})
+	a := NewAliasSampler(d)
+	r := rand.New(rand.NewSource(1))
+
+	const n = 100000
+	counts := make(map[Outcome]int)
+	for i := 0; i < n; i++ {
+		counts[a.Draw(r)]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		if freq := float64(counts[o]) / n; math.Abs(freq-0.5) > 0.01 {
+			t.Errorf("frequency of %v = %f, want 0.5", o, freq)
+		}
+	}
+}
+
+func BenchmarkAliasSampler(b *testing.B) {
+	a := NewAliasSampler(large(1000))
+	r := rand.New(rand.NewSource(1))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		a.Draw(r)
+	}
+}
+
+func TestShuffleOutcomes(t *testing.T) {
+	os := Outcomes{1, 2, 3, 4, 5, 6, 7, 8}
+
+	s1 := ShuffleOutcomes(os, rand.New(rand.NewSource(7)))
+	s2 := ShuffleOutcomes(os, rand.New(rand.NewSource(7)))
+
+	counts := make(map[Outcome]int)
+	for i := range os {
+		if s1[i] != s2[i] {
+			t.Errorf("permutations from the same seed differ at %d: %v, %v", i, s1, s2)
+		}
+
+		if os[i] != i+1 {
+			t.Errorf("input modified: %v", os)
+		}
+
+		counts[s1[i]]++
+	}
+
+	for _, o := range os {
+		if counts[o] != 1 {
+			t.Errorf("%v appears %d times in %v, want once", o, counts[o], s1)
+		}
+	}
+}
+
+func TestThin(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	times := make([]float64, 100000)
+	for i := range times {
+		times[i] = float64(i) + r.Float64()
+	}
+
+	kept := Thin(times, 0.3, r)
+
+	if frac := float64(len(kept)) / float64(len(times)); math.Abs(frac-0.3) > 0.01 {
+		t.Errorf("kept %f of the events, want 0.3", frac)
+	}
+
+	for i := 1; i < len(kept); i++ {
+		if kept[i] <= kept[i-1] {
+			t.Fatalf("kept times out of order at %d", i)
+		}
+	}
+
+	if kept := Thin(times, Certain, r); len(kept) != len(times) {
+		t.Errorf("Thin(times, 1) kept %d of %d events", len(kept), len(times))
+	}
+}
+
+func TestMonteCarloExpectation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	normal := func(r *rand.Rand) float64 { return r.NormFloat64() }
+	square := func(x float64) float64 { return x * x }
+
+	// the standard deviation of the estimate is sqrt(2/n) ≈ 0.0045
+	if e := MonteCarloExpectation(normal, square, 100000, r); math.Abs(e-1) > 0.02 {
+		t.Errorf("E[X^2] of a standard normal ≈ %f, want 1", e)
+	}
+}
+
+func TestSamplePMF(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pmf := Geometric(0.3)
+
+	support := make([]int, 200)
+	for i := range support {
+		support[i] = i + 1
+	}
+
+	const n = 100000
+	sum := 0
+	for _, k := range SamplePMFs(pmf, support, n, r) {
+		sum += k
+	}
+
+	// the standard deviation of the sample mean is sqrt((1-p)/p²/n) ≈ 0.009
+	if mean := float64(sum) / n; math.Abs(mean-1/0.3) > 0.05 {
+		t.Errorf("sample mean of Geometric(0.3) = %f, want %f", mean, 1/0.3)
+	}
+
+	if k := SamplePMF(pmf, support, r); k < 1 || k > 200 {
+		t.Errorf("SamplePMF = %d, want in the support [1, 200]", k)
+	}
+}
+
+func TestStratifiedSample(t *testing.T) {
+	d := die()
+	r := rand.New(rand.NewSource(1))
+
+	// squared error of the frequencies of samples
+	spread := func(samples Outcomes) float64 {
+		counts := make(map[Outcome]int)
+		for _, o := range samples {
+			counts[o]++
+		}
+
+		s := 0.0
+		for _, o := range d.Outcomes().Elements() {
+			s += math.Pow(float64(counts[o])/float64(len(samples))-1.0/6, 2)
+		}
+
+		return s
+	}
+
+	const trials, n = 200, 50
+	stratified, independent := 0.0, 0.0
+
+	for i := 0; i < trials; i++ {
+		stratified += spread(StratifiedSample(d, n, r))
+
+		samples := make(Outcomes, n)
+		for j := range samples {
+			samples[j] = SimulateWith(d, r)
+		}
+		independent += spread(samples)
+	}
+
+	if stratified >= independent {
+		t.Errorf("stratified frequency variance %f, want below the independent %f", stratified/trials, independent/trials)
+	}
+}
+
+func TestStratifiedSampleUnderSupported(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2), map[Outcome]Probability{1: 0.49, 2: 0.49})
+	r := rand.New(rand.NewSource(1))
+
+	// the strata in the lower half of [0, 1) yield 1, the rest 2
+	counts := make(map[Outcome]int)
+	for _, o := range StratifiedSample(d, 100, r) {
+		counts[o]++
+	}
+
+	if counts[1] != 50 || counts[2] != 50 {
+		t.Errorf("StratifiedSample counts = %v, want 50 of each", counts)
+	}
+}
+
+func TestParallelSample(t *testing.T) {
+	d := NewCategorical(Outcomes{1, 2, 3, 4}, []float64{0.1, 0.2, 0.3, 0.4})
+
+	const n = 100000
+	samples := ParallelSample(d, n)
+
+	if len(samples) != n {
+		t.Fatalf("len(ParallelSample(d, %d)) = %d", n, len(samples))
+	}
+
+	counts := make(map[Outcome]int)
+	for _, o := range samples {
+		counts[o]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		freq, p := float64(counts[o])/n, float64(d.ProbabilityOf(o))
+		if math.Abs(freq-p) > 0.01 {
+			t.Errorf("frequency of %v = %f, want %f", o, freq, p)
+		}
+	}
+
+	// fewer samples than shards
+	if s := ParallelSample(d, 1); len(s) != 1 || s[0] == nil {
+		t.Errorf("ParallelSample(d, 1) = %v, want one outcome", s)
+	}
+}
+
+func TestParallelSampleUnderSupported(t *testing.T) {
+	d := newDistribution(set.WithElements(1, 2), map[Outcome]Probability{1: 0.49, 2: 0.49})
+
+	const n = 100000
+	counts := make(map[Outcome]int)
+	for _, o := range ParallelSample(d, n) {
+		counts[o]++
+	}
+
+	for _, o := range d.Outcomes().Elements() {
+		if freq := float64(counts[o]) / n; math.Abs(freq-0.5) > 0.01 {
+			t.Errorf("frequency of %v = %f, want 0.5", o, freq)
+		}
+	}
+}
+
+func BenchmarkParallelSample(b *testing.B) {
+	d := die()
+
+	for i := 0; i < b.N; i++ {
+		ParallelSample(d, 100000)
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	const n, k, trials = 10, 3, 20000
+	counts := make(map[Outcome]int)
+
+	for i := 0; i < trials; i++ {
+		stream := make(chan Outcome, n)
+		for j := 0; j < n; j++ {
+			stream <- j
+		}
+		close(stream)
+
+		reservoir := ReservoirSample(stream, k, r)
+		if len(reservoir) != k {
+			t.Fatalf("len(ReservoirSample) = %d, want %d", len(reservoir), k)
+		}
+
+		for _, o := range reservoir {
+			counts[o]++
+		}
+	}
+
+	for j := 0; j < n; j++ {
+		if freq := float64(counts[j]) / trials; math.Abs(freq-float64(k)/n) > 0.02 {
+			t.Errorf("%d kept with frequency %f, want %f", j, freq, float64(k)/n)
+		}
+	}
+
+	short := make(chan Outcome, 2)
+	short <- 1
+	short <- 2
+	close(short)
+
+	if reservoir := ReservoirSample(short, k, r); len(reservoir) != 2 {
+		t.Errorf("ReservoirSample of 2 outcomes = %v, want both", reservoir)
+	}
+}
@@ -0,0 +1,95 @@
+package prob
+
+import "math"
+
+// --- Summary {{{
+
+// A Summary describes a random variable over a discrete distribution,
+// and the distribution itself. See Describe.
+type Summary struct {
+	// Mean, Variance and StdDev describe the random variable
+	Mean, Variance, StdDev float64
+
+	// Entropy is the entropy of the distribution, in bits
+	Entropy float64
+
+	// Mode is the set of most probable outcomes of the distribution
+	Mode Outcomes
+
+	// Cardinality is the number of outcomes of the distribution
+	Cardinality uint
+}
+
+// Describe summarizes the random variable X over the distribution d,
+// in a single pass over the outcomes of d.
+func Describe(d DiscreteDistribution, X RandomVariable) Summary {
+	var s Summary
+	var sq float64
+	var m modes
+
+	for _, o := range sorted(d.Outcomes().Elements()) {
+		p, x := d.ProbabilityOf(o), X(o)
+
+		s.Mean += x * float64(p)
+		sq += x * x * float64(p)
+
+		if p != Impossible {
+			s.Entropy -= float64(p) * math.Log2(float64(p))
+		}
+
+		m.add(o, p)
+		s.Cardinality++
+	}
+
+	s.Mode = m.outcomes
+
+	s.Variance = sq - s.Mean*s.Mean
+	s.StdDev = math.Sqrt(math.Max(0, s.Variance))
+
+	return s
+}
+
+// --- }}}
+
+// --- Running Statistics {{{
+
+// RunningStats accumulates the mean and variance of a stream of
+// observations, e.g., the outcomes of repeated simulation, without
+// storing them. It uses Welford's algorithm, which is numerically
+// stable. The zero value is ready to use.
+type RunningStats struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+// Push records the observation x
+func (s *RunningStats) Push(x float64) {
+	s.n++
+
+	delta := x - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (x - s.mean)
+}
+
+// Count returns the number of observations
+func (s *RunningStats) Count() int {
+	return s.n
+}
+
+// Mean returns the mean of the observations, or 0 if there are none
+func (s *RunningStats) Mean() float64 {
+	return s.mean
+}
+
+// Variance returns the (population) variance of the observations,
+// or 0 if there are none. See VarianceSlices.
+func (s *RunningStats) Variance() float64 {
+	if s.n == 0 {
+		return 0
+	}
+
+	return s.m2 / float64(s.n)
+}
+
+// --- }}}
@@ -0,0 +1,83 @@
+package prob
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	for _, c := range []struct {
+		d    DiscreteDistribution
+		mode Outcomes
+	}{
+		{die(), Outcomes{1, 2, 3, 4, 5, 6}},
+		{NewCategorical(Outcomes{1, 2, 3, 4}, []float64{0.1, 0.4, 0.4, 0.1}), Outcomes{2, 3}},
+		{NewCategorical(Outcomes{1, 2, 3}, []float64{0.7, 0.2, 0.1}), Outcomes{1}},
+	} {
+		d := c.d
+		s := Describe(d, value)
+
+		if !equiv(s.Mean, Expectation(d, value)) {
+			t.Errorf("Mean = %f, want %f", s.Mean, Expectation(d, value))
+		}
+
+		if !equiv(s.Variance, Variance(d, value)) {
+			t.Errorf("Variance = %f, want %f", s.Variance, Variance(d, value))
+		}
+
+		if !equiv(s.StdDev, math.Sqrt(Variance(d, value))) {
+			t.Errorf("StdDev = %f, want %f", s.StdDev, math.Sqrt(Variance(d, value)))
+		}
+
+		if !equiv(s.Entropy, Entropy(d)) {
+			t.Errorf("Entropy = %f, want %f", s.Entropy, Entropy(d))
+		}
+
+		if s.Cardinality != Cardinality(d) {
+			t.Errorf("Cardinality = %d, want %d", s.Cardinality, Cardinality(d))
+		}
+
+		mode := c.mode
+		if len(s.Mode) != len(mode) {
+			t.Fatalf("Mode = %v, want %v", s.Mode, mode)
+		}
+
+		for i := range mode {
+			if s.Mode[i] != mode[i] {
+				t.Errorf("Mode = %v, want %v", s.Mode, mode)
+			}
+		}
+	}
+}
+
+func TestRunningStats(t *testing.T) {
+	d := die()
+	r := rand.New(rand.NewSource(1))
+
+	const n = 100000
+	var s RunningStats
+	values := make([]float64, n)
+	probs := make([]Probability, n)
+
+	for i := range values {
+		values[i], probs[i] = value(SimulateWith(d, r)), Probability(1.0/n)
+		s.Push(values[i])
+	}
+
+	if s.Count() != n {
+		t.Errorf("Count = %d, want %d", s.Count(), n)
+	}
+
+	if math.Abs(s.Mean()-3.5) > 0.02 {
+		t.Errorf("Mean = %f, want 3.5", s.Mean())
+	}
+
+	if v := Variance(d, value); math.Abs(s.Variance()-v) > 0.05 {
+		t.Errorf("Variance = %f, want %f", s.Variance(), v)
+	}
+
+	if v := VarianceSlices(values, probs); !equiv(s.Variance(), v) {
+		t.Errorf("Variance = %f, want the batch variance %f", s.Variance(), v)
+	}
+}
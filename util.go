@@ -1,9 +1,72 @@
 package prob
 
-// assert is a helper function to provide
-// moderate runtime type checking on the Element interface
-func assert(flag bool, s string) {
-	if !flag {
-		panic(s)
+import (
+	"sort"
+
+	"github.com/nlandolfi/set"
+)
+
+// integer is a helper for distributions over integer outcomes,
+// asserting an outcome is an int
+func integer(o Outcome) int {
+	k, ok := o.(int)
+	assert(ok, "outcome is not an integer")
+	return k
+}
+
+// sorted copies the outcomes into a stable order, by value, when each
+// outcome is an int, int64, float64 or string. Outcomes of any other
+// type have no such order, so they are copied in the order given.
+func sorted(os []set.Element) Outcomes {
+	s := make(Outcomes, len(os))
+	copy(s, os)
+
+	for _, o := range s {
+		if rank(o) < 0 {
+			return s
+		}
 	}
+
+	sort.Slice(s, func(i, j int) bool {
+		return less(s[i], s[j])
+	})
+
+	return s
+}
+
+// rank orders the types of outcome which sorted can compare, and
+// is -1 for any other type
+func rank(o Outcome) int {
+	switch o.(type) {
+	case int:
+		return 0
+	case int64:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	}
+
+	return -1
+}
+
+// less defines the order used by sorted: first by type, then by value
+func less(a, b Outcome) bool {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+
+	switch x := a.(type) {
+	case int:
+		return x < b.(int)
+	case int64:
+		return x < b.(int64)
+	case float64:
+		return x < b.(float64)
+	case string:
+		return x < b.(string)
+	}
+
+	return false
 }
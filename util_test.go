@@ -0,0 +1,20 @@
+package prob
+
+import "testing"
+
+func TestSorted(t *testing.T) {
+	got := sorted(Outcomes{"b", 2.5, 3, "a", 1})
+	want := Outcomes{1, 3, 2.5, "a", "b"}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sorted = %v, want %v", got, want)
+		}
+	}
+
+	// pairs have no order, so they are left as given
+	pairs := Outcomes{Pair{2, 1}, Pair{1, 2}}
+	if got := sorted(pairs); got[0] != pairs[0] || got[1] != pairs[1] {
+		t.Errorf("sorted(%v) = %v, want the order unchanged", pairs, got)
+	}
+}